@@ -27,13 +27,17 @@ elif [[ -z "$VERSION_BRANCH" && -z "$VERSION_TAG" ]]; then # If in detached HEAD
 fi
 
 
-echo "Building version: $VERSION"
+COMMIT=$(git rev-parse --short HEAD 2>/dev/null || echo "unknown")
+BUILD_DATE=$(date -u +%Y-%m-%dT%H:%M:%SZ)
+VERSION_PKG="github.com/markomanboi/github-actions-exporter/pkg/version"
+
+echo "Building version: $VERSION (commit $COMMIT, built $BUILD_DATE)"
 
 # Ensure the bin directory exists
 mkdir -p bin
 
 # Build the application
-CGO_ENABLED=0 GOOS=linux go build -a -installsuffix cgo -ldflags="-X 'main.version=$VERSION'" -v -o bin/app .
+CGO_ENABLED=0 GOOS=linux go build -a -installsuffix cgo -ldflags="-X '$VERSION_PKG.Version=$VERSION' -X '$VERSION_PKG.Commit=$COMMIT' -X '$VERSION_PKG.BuildDate=$BUILD_DATE'" -v -o bin/app .
 # Added -v for verbose build output
 # Added . at the end to specify current directory as the package to build (if your main package is there)
 # Or specify the path to your main package e.g., ./cmd/exporter
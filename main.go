@@ -1,25 +1,37 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"os"
 
 	"github.com/urfave/cli/v2"
 
-	"github.com/spendesk/github-actions-exporter/pkg/config"
-	"github.com/spendesk/github-actions-exporter/pkg/server"
-)
-
-var (
-	version = "development"
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+	"github.com/markomanboi/github-actions-exporter/pkg/server"
+	"github.com/markomanboi/github-actions-exporter/pkg/version"
 )
 
 func main() {
+	cli.VersionPrinter = func(ctx *cli.Context) {
+		fmt.Printf("%s %s\n", ctx.App.Name, version.String())
+	}
+
 	app := cli.NewApp()
 	app.Name = "github-actions-exporter"
 	app.Flags = config.InitConfiguration()
-	app.Version = version
+	app.Version = version.Version
 	app.Action = server.RunServer
+	app.Commands = []*cli.Command{
+		{
+			Name:  "version",
+			Usage: "Print the version, commit and build date",
+			Action: func(ctx *cli.Context) error {
+				cli.ShowVersion(ctx)
+				return nil
+			},
+		},
+	}
 
 	err := app.Run(os.Args)
 	if err != nil {
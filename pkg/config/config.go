@@ -8,22 +8,99 @@ var (
 		AppID                             int64  `split_words:"true"`
 		AppInstallationID                 int64  `split_words:"true"`
 		AppPrivateKey                     string `split_words:"true"`
+		AppPrivateKeys                    cli.StringSlice // Additional private keys of the App, tried in order when a key is rejected
 		Token                             string
+		TokenFile                         string // File holding the token, re-read when the client is rebuilt
 		Refresh                           int64 // Refresh time for main data fetching loop (workflow runs, etc.)
+		AutoTuneRefresh                   bool  // Lengthen Refresh when observed cycles don't fit in it
+		SpreadRepoFetches                 bool  // Pace repository fetches evenly across Refresh
+		RepoScheduling                    string // config or smallest_first: order of the repositories in a workflow run cycle
+		FetchConcurrency                  int   // Maximum number of concurrent fetches (organizations, repositories)
+		PerPage                           int   // Page size of API list calls
 		Repositories                      cli.StringSlice
 		Organizations                     cli.StringSlice // Note: Current code mainly uses Repositories directly for workflow runs. Org support would need expansion.
 		APIURL                            string
 		CacheSizeBytes                    int64
 		FetchMaxWorkflowCreationAgeHours  int64 `mapstructure:"fetch_max_workflow_creation_age_hours"` // New: How far back to look for "created" workflow runs
+		FetchRecentlyCompletedHours       int64 // Also fetch the runs created earlier but completed within this many hours, disabled when 0
 		WorkflowCacheRefreshIntervalSeconds int64 `mapstructure:"workflow_cache_refresh_interval_seconds"` // New: How often to refresh workflow ID->name cache
+		SkipReposWithoutWorkflows         bool // Don't list runs of repositories known to have no workflows
+		MaxCachedWorkflows                int  // Bound of the workflow definitions cache, unbounded when 0
+		SkipDormantWorkflowDays           int64 // Repositories whose workflows had no run for this many days are polled hourly
+		DefaultBranchOnly                 bool // Only export runs of the default branch of each repository
+		RepoVisibilityFilter              string // all, public or private: visibility of the monitored repositories
+		ReportBlockedRepos                bool // Export github_repo_actions_blocked for archived, disabled or suspended repositories
+		FetchStrategy                     string // repo (list the runs of every repository) or search
+		SearchWorkflowFiles               cli.StringSlice // Workflow file names located with the code search when FetchStrategy is search
+		PrimeCacheOnStartup               bool // Wait for the first repository and workflow definition fetch before serving metrics
+		PrimeCacheTimeoutSeconds          int64 // Bound of the wait of PrimeCacheOnStartup
+		MaxRateLimitSleepSeconds          int64 // Bound of the pause of a fetcher until a rate limit reset
+		EnterpriseDiscoverAllOrgs         bool // Discover the repositories of every organization of EnterpriseName
+		StartupJitterSeconds              int64 // Maximum random delay before the first tick of each fetcher
+		TickJitterSeconds                 int64 // Maximum random delay before each collection cycle
+		JitterSeed                        int64 // Seed of the jitters, random when 0
 	}
 	Metrics struct {
-		FetchWorkflowRunUsage bool
+		FetchWorkflowRunUsage            bool
+		UsageMinEstimatedDurationSeconds int64           // Runs estimated shorter than this skip the usage API call
+		MaxPlausibleRunDurationHours     int64           // Run durations estimated longer than this are unknown, no cap when 0
+		UseCreatedAsStartFallback        bool            // Use CreatedAt as the start of runs missing RunStartedAt
+		DurationExcludeConclusions       cli.StringSlice // Conclusions of runs whose duration isn't exported
+		DropStaleRuns                    bool            // Don't export any metric of the runs GitHub marked stale
+		FetchWorkflowJobs                bool
+		SelfHostedRunnerLabels           cli.StringSlice // A job requesting any of these labels is classified as self-hosted
+		FailureConclusions               cli.StringSlice // Run conclusions counted as failures by github_workflow_run_failed
+		ConclusionBuckets                string          // JSON object of run conclusion to the bucket exported as conclusion_bucket
+		ResolvePRFromCommit              bool
+		FetchCheckRuns                   bool
+		FetchDeployments                 bool
+		FetchRunners                     bool
+		FetchCacheUsage                  bool
+		FetchRepoBilling                 bool            // Sum the billable usage of the workflows of each private repository
+		FetchOrgSecretsCount             bool            // Count the Actions secrets and variables of each organization
+		FetchOrgActionsPermissions       bool            // Export the Actions policy of each organization
+		FetchScaleSets                   bool            // Export the replicas of the runner groups of each organization (ARC scale sets)
+		CacheUsageRefresh                int64           // Refresh time for the Actions cache usage, slower than Refresh
+		RunnerStatusValueMap             string          // JSON object of runner status to gauge value
+		DefaultStatusValue               float64         // Run status value of unmapped statuses
+		FailureStatusValue               float64         // Run status value of failed runs
+		SampleRateOverrides              cli.StringSlice // <owner>/<repo>=<rate> entries, rate being the fraction of runs exported
+		DispatchInputKey                 string          // workflow_dispatch input exported as the dispatch_input field
+		PathDerivedLabelRegex            string          // Regex over the workflow path whose named capture groups become labels
+		CommitTitleMode                  string          // first_line, full_truncated or none: value of the derived_commit_pr_title field
+		CommitTitleMaxLength             int             // Length of derived_commit_pr_title in full_truncated mode
+		MaxRunsPerCycle                  int             // Budget of runs exported per workflow run collection cycle, unlimited when 0
+		LabelValueAllowlist              cli.StringSlice // <field>=<pattern> entries, values of the field matching no pattern are replaced
+		LabelValueOther                  string          // Replacement of the values left out of LabelValueAllowlist
+		EmptyLabelPlaceholder            string          // Replacement of the empty values of the workflow run labels, none when empty
 	}
-	Port           int
-	Debug          bool
-	EnterpriseName string // Used for enterprise-specific runner/billing metrics, not directly for core workflow runs
-	WorkflowFields string // Comma-separated list of labels for github_workflow_run_status
+	RemoteWrite struct {
+		URL         string
+		Interval    int64
+		BearerToken string
+	}
+	Pushgateway struct {
+		URL      string
+		Job      string
+		Instance string
+	}
+	Traces struct {
+		Enabled     bool   // Emit completed runs and jobs as spans
+		Endpoint    string // OTLP/HTTP endpoint, /v1/traces is appended
+		ServiceName string
+	}
+	Port               int
+	MetricsFormat      string          // "openmetrics" (negotiated with the Accept header) or "text"
+	MetricNamespace    string          // Prefix of all metric names
+	StaticLabels       cli.StringSlice // key=value labels added to all metrics
+	Debug              bool
+	RunOnce            bool   // Collect once and exit instead of serving /metrics
+	WarmupCycles       int    // /metrics answers 503 until every fetcher completed this many cycles
+	TextfileOutputPath string // .prom file rewritten after each cycle for the node_exporter textfile collector
+	WebhookSecret      string // Enables the /webhook endpoint, deliveries must be signed with it
+	AdminToken         string // Enables the /admin endpoints, requests must carry it as a bearer token
+	EnterpriseName     string // Used for enterprise-specific runner/billing metrics, not directly for core workflow runs
+	WorkflowFields     string // Comma-separated list of labels for github_workflow_run_status
 )
 
 // InitConfiguration - set configuration from env vars or command parameters
@@ -50,6 +127,12 @@ func InitConfiguration() []cli.Flag {
 			Usage:       "Github App Private Key",
 			Destination: &Github.AppPrivateKey,
 		},
+		&cli.StringSliceFlag{
+			Name:        "app_private_keys",
+			EnvVars:     []string{"GITHUB_APP_PRIVATE_KEYS"},
+			Usage:       "Additional Github App Private Keys, tried in order when GitHub rejects the current one. Allows rotating keys without downtime",
+			Destination: &Github.AppPrivateKeys,
+		},
 		&cli.IntFlag{
 			Name:        "port",
 			Aliases:     []string{"p"},
@@ -58,6 +141,114 @@ func InitConfiguration() []cli.Flag {
 			Usage:       "Exporter port",
 			Destination: &Port,
 		},
+		&cli.StringFlag{
+			Name:        "metrics_format",
+			EnvVars:     []string{"METRICS_FORMAT"},
+			Value:       "openmetrics",
+			Usage:       "Exposition format of /metrics: openmetrics (served when requested by the Accept header) or text (always Prometheus text format)",
+			Destination: &MetricsFormat,
+		},
+		&cli.IntFlag{
+			Name:        "warmup_cycles",
+			EnvVars:     []string{"WARMUP_CYCLES"},
+			Value:       0,
+			Usage:       "Answer 503 on /metrics until every fetcher completed this many cycles without failing entirely, so scrapes don't see the partial data of the first cycles. 0 serves /metrics right away",
+			Destination: &WarmupCycles,
+		},
+		&cli.StringFlag{
+			Name:        "metric_namespace",
+			EnvVars:     []string{"METRIC_NAMESPACE"},
+			Usage:       "Prefix of all metric names, like myorg for myorg_github_workflow_run_status, to avoid collisions with other GitHub exporters",
+			Destination: &MetricNamespace,
+		},
+		&cli.StringSliceFlag{
+			Name:        "static_labels",
+			EnvVars:     []string{"STATIC_LABELS"},
+			Usage:       "Labels added to all metrics, formatted as key=value,key2=value2 (like environment=prod,team=platform)",
+			Destination: &StaticLabels,
+		},
+		&cli.StringFlag{
+			Name:        "remote_write_url",
+			EnvVars:     []string{"REMOTE_WRITE_URL"},
+			Usage:       "Prometheus remote write endpoint to push the metrics to, in addition to serving /metrics",
+			Destination: &RemoteWrite.URL,
+		},
+		&cli.Int64Flag{
+			Name:        "remote_write_interval",
+			EnvVars:     []string{"REMOTE_WRITE_INTERVAL"},
+			Usage:       "Interval in sec between two remote writes (defaults to github_refresh)",
+			Destination: &RemoteWrite.Interval,
+		},
+		&cli.StringFlag{
+			Name:        "remote_write_bearer_token",
+			EnvVars:     []string{"REMOTE_WRITE_BEARER_TOKEN"},
+			Usage:       "Bearer token sent to the remote write endpoint",
+			Destination: &RemoteWrite.BearerToken,
+		},
+		&cli.StringFlag{
+			Name:        "webhook_secret",
+			EnvVars:     []string{"WEBHOOK_SECRET"},
+			Usage:       "Secret of the GitHub webhook sending workflow_run and workflow_job events to /webhook. Repositories sending them are no longer polled for workflow runs. The endpoint is disabled when empty",
+			Destination: &WebhookSecret,
+		},
+		&cli.StringFlag{
+			Name:        "admin_token",
+			EnvVars:     []string{"ADMIN_TOKEN"},
+			Usage:       "Bearer token of the /admin/pause and /admin/resume endpoints, which stop and restart metric collection. The endpoints are disabled when empty",
+			Destination: &AdminToken,
+		},
+		&cli.StringFlag{
+			Name:        "textfile_output_path",
+			EnvVars:     []string{"TEXTFILE_OUTPUT_PATH"},
+			Usage:       "Path of a .prom file rewritten after each workflow run collection cycle, for the node_exporter textfile collector",
+			Destination: &TextfileOutputPath,
+		},
+		&cli.BoolFlag{
+			Name:        "once",
+			EnvVars:     []string{"RUN_ONCE"},
+			Value:       false,
+			Usage:       "Run a single collection cycle of every fetcher, export the metrics (see PUSHGATEWAY_URL, TEXTFILE_OUTPUT_PATH) and exit instead of serving /metrics",
+			Destination: &RunOnce,
+		},
+		&cli.StringFlag{
+			Name:        "pushgateway_url",
+			EnvVars:     []string{"PUSHGATEWAY_URL"},
+			Usage:       "Prometheus Pushgateway to push the metrics to after each workflow run collection cycle, in addition to serving /metrics",
+			Destination: &Pushgateway.URL,
+		},
+		&cli.StringFlag{
+			Name:        "pushgateway_job",
+			EnvVars:     []string{"PUSHGATEWAY_JOB"},
+			Value:       "github-actions-exporter",
+			Usage:       "Job label of the metrics pushed to the Pushgateway",
+			Destination: &Pushgateway.Job,
+		},
+		&cli.StringFlag{
+			Name:        "pushgateway_instance",
+			EnvVars:     []string{"PUSHGATEWAY_INSTANCE"},
+			Usage:       "Instance label of the metrics pushed to the Pushgateway (defaults to the hostname)",
+			Destination: &Pushgateway.Instance,
+		},
+		&cli.BoolFlag{
+			Name:        "emit_traces",
+			EnvVars:     []string{"EMIT_TRACES"},
+			Usage:       "When true, completed workflow runs (and their jobs with fetch_workflow_jobs) are sent as OpenTelemetry spans to otel_exporter_otlp_endpoint",
+			Destination: &Traces.Enabled,
+		},
+		&cli.StringFlag{
+			Name:        "otel_exporter_otlp_endpoint",
+			EnvVars:     []string{"OTEL_EXPORTER_OTLP_ENDPOINT"},
+			Value:       "http://localhost:4318",
+			Usage:       "OTLP/HTTP endpoint of the OpenTelemetry collector receiving the spans of emit_traces, /v1/traces is appended",
+			Destination: &Traces.Endpoint,
+		},
+		&cli.StringFlag{
+			Name:        "otel_service_name",
+			EnvVars:     []string{"OTEL_SERVICE_NAME"},
+			Value:       "github-actions",
+			Usage:       "service.name resource attribute of the spans of emit_traces",
+			Destination: &Traces.ServiceName,
+		},
 		&cli.StringFlag{
 			Name:        "github_token",
 			Aliases:     []string{"gt"},
@@ -65,6 +256,12 @@ func InitConfiguration() []cli.Flag {
 			Usage:       "Github Personal Token",
 			Destination: &Github.Token,
 		},
+		&cli.StringFlag{
+			Name:        "github_token_file",
+			EnvVars:     []string{"GITHUB_TOKEN_FILE"},
+			Usage:       "File holding the Github Personal Token, re-read when the client is rebuilt after authentication failures (takes precedence over github_token)",
+			Destination: &Github.TokenFile,
+		},
 		&cli.Int64Flag{
 			Name:        "github_refresh",
 			Aliases:     []string{"gr"},
@@ -73,6 +270,62 @@ func InitConfiguration() []cli.Flag {
 			Usage:       "Refresh time for fetching workflow runs and other primary metrics in sec",
 			Destination: &Github.Refresh,
 		},
+		&cli.BoolFlag{
+			Name:        "auto_tune_refresh",
+			EnvVars:     []string{"AUTO_TUNE_REFRESH"},
+			Usage:       "When true, lengthens the workflow run refresh if collection cycles take longer than it, instead of only logging a warning",
+			Value:       false,
+			Destination: &Github.AutoTuneRefresh,
+		},
+		&cli.BoolFlag{
+			Name:        "spread_repo_fetches",
+			EnvVars:     []string{"SPREAD_REPO_FETCHES"},
+			Usage:       "When true, workflow run fetches of the repositories are spread evenly across the refresh interval instead of bursting at each tick",
+			Value:       false,
+			Destination: &Github.SpreadRepoFetches,
+		},
+		&cli.StringFlag{
+			Name:        "repo_scheduling",
+			EnvVars:     []string{"REPO_SCHEDULING"},
+			Value:       "config",
+			Usage:       "Order of the repositories in a workflow run cycle: config (as configured or discovered) or smallest_first (fewest runs on average first, so giant repositories don't delay the others)",
+			Destination: &Github.RepoScheduling,
+		},
+		&cli.Int64Flag{
+			Name:        "startup_jitter_seconds",
+			EnvVars:     []string{"STARTUP_JITTER_SECONDS"},
+			Value:       0,
+			Usage:       "Delay the first tick of each fetcher by a random duration up to this many seconds, so replicas don't query GitHub in lockstep",
+			Destination: &Github.StartupJitterSeconds,
+		},
+		&cli.Int64Flag{
+			Name:        "tick_jitter_seconds",
+			EnvVars:     []string{"TICK_JITTER_SECONDS"},
+			Value:       0,
+			Usage:       "Delay each collection cycle by a random duration up to this many seconds",
+			Destination: &Github.TickJitterSeconds,
+		},
+		&cli.Int64Flag{
+			Name:        "jitter_seed",
+			EnvVars:     []string{"JITTER_SEED"},
+			Value:       0,
+			Usage:       "Seed of the random jitters, for reproducible delays. Random when 0",
+			Destination: &Github.JitterSeed,
+		},
+		&cli.IntFlag{
+			Name:        "fetch_concurrency",
+			EnvVars:     []string{"FETCH_CONCURRENCY"},
+			Value:       4,
+			Usage:       "Maximum number of concurrent fetches, e.g. of organization runners or of the workflow definitions of repositories",
+			Destination: &Github.FetchConcurrency,
+		},
+		&cli.IntFlag{
+			Name:        "github_per_page",
+			EnvVars:     []string{"GITHUB_PER_PAGE"},
+			Value:       100,
+			Usage:       "Page size of API list calls (1-100). Smaller pages can help GitHub Enterprise Servers under load",
+			Destination: &Github.PerPage,
+		},
 		&cli.StringFlag{
 			Name:        "github_api_url",
 			Aliases:     []string{"url"},
@@ -98,7 +351,7 @@ func InitConfiguration() []cli.Flag {
 		&cli.BoolFlag{
 			Name:        "debug_profile",
 			EnvVars:     []string{"DEBUG_PROFILE"},
-			Usage:       "Expose pprof information on /debug/pprof/",
+			Usage:       "Expose pprof information on /debug/pprof/ and the monitored repositories on /debug/repositories",
 			Destination: &Debug,
 		},
 		&cli.StringFlag{
@@ -108,13 +361,20 @@ func InitConfiguration() []cli.Flag {
 			Destination: &EnterpriseName,
 			Value:       "",
 		},
+		&cli.BoolFlag{
+			Name:        "enterprise_discover_all_orgs",
+			EnvVars:     []string{"ENTERPRISE_DISCOVER_ALL_ORGS"},
+			Value:       false,
+			Usage:       "Discover the repositories of every organization of the enterprise (enterprise_name) in addition to github_orgas, when github_repos is not set",
+			Destination: &Github.EnterpriseDiscoverAllOrgs,
+		},
 		&cli.StringFlag{
 			Name:    "export_fields", // Original name: "export_fields"
 			EnvVars: []string{"EXPORT_FIELDS_WORKFLOW_RUN"}, // Changed EnvVar to be more specific
-			Usage: "A comma-separated, ordered list of labels for github_workflow_run_status metric. " +
-				"Order matters and must align with internal logic.",
+			Usage: "A comma-separated list of labels for github_workflow_run_status metric, in the order they are registered. " +
+				"Each field must be supported and listed once, the exporter refuses to start otherwise.",
 			// Updated default value to reflect the new, richer set of fields.
-			// Ensure this order is respected in getFieldValue and label construction.
+			// Fields are validated at startup against the ones resolved by the metrics package.
 			Value: "repo,workflow_id,workflow_name,run_id,run_number,run_attempt,event,status,conclusion,head_branch," +
 				"derived_target_branch,pr_number,derived_commit_pr_title,display_title,actor_login,triggering_actor_login," +
 				"created_at_unix,updated_at_unix,run_started_at_unix,path",
@@ -127,6 +387,217 @@ func InitConfiguration() []cli.Flag {
 			Value:       true,
 			Destination: &Metrics.FetchWorkflowRunUsage,
 		},
+		&cli.Int64Flag{
+			Name:        "usage_min_estimated_duration_seconds",
+			EnvVars:     []string{"USAGE_MIN_ESTIMATED_DURATION_SECONDS"},
+			Value:       0,
+			Usage:       "Skip the workflow usage API call for completed runs whose duration estimated from their timestamps is shorter than this, and export the estimate instead",
+			Destination: &Metrics.UsageMinEstimatedDurationSeconds,
+		},
+		&cli.Int64Flag{
+			Name:        "max_plausible_run_duration_hours",
+			EnvVars:     []string{"MAX_PLAUSIBLE_RUN_DURATION_HOURS"},
+			Value:       72,
+			Usage:       "Treat run durations estimated from their timestamps (when the usage API is skipped or fails) longer than this as unknown, since UpdatedAt also changes for other reasons than the completion. 0 disables the cap",
+			Destination: &Metrics.MaxPlausibleRunDurationHours,
+		},
+		&cli.BoolFlag{
+			Name:        "use_created_as_start_fallback",
+			EnvVars:     []string{"USE_CREATED_AS_START_FALLBACK"},
+			Value:       false,
+			Usage:       "Use the creation time of runs missing their start time for run_started_at_unix and the estimated durations, which then include the queuing time",
+			Destination: &Metrics.UseCreatedAsStartFallback,
+		},
+		&cli.StringSliceFlag{
+			Name:        "duration_exclude_conclusions",
+			EnvVars:     []string{"DURATION_EXCLUDE_CONCLUSIONS"},
+			Value:       cli.NewStringSlice("cancelled", "skipped"),
+			Usage:       "Don't export the duration of runs with these conclusions, whose near-zero or time-to-cancel durations skew averages",
+			Destination: &Metrics.DurationExcludeConclusions,
+		},
+		&cli.BoolFlag{
+			Name:        "drop_stale_runs",
+			EnvVars:     []string{"DROP_STALE_RUNS"},
+			Value:       false,
+			Usage:       "Don't export any metric of the runs GitHub marked stale (not updated in 7 days). Kept stale runs have an unknown duration",
+			Destination: &Metrics.DropStaleRuns,
+		},
+		&cli.BoolFlag{
+			Name:        "fetch_workflow_jobs",
+			EnvVars:     []string{"FETCH_WORKFLOW_JOBS"},
+			Usage:       "When true, will perform an API call per workflow run to fetch its jobs (runner type, labels, etc.)",
+			Value:       false,
+			Destination: &Metrics.FetchWorkflowJobs,
+		},
+		&cli.StringSliceFlag{
+			Name:        "sample_rate_overrides",
+			EnvVars:     []string{"SAMPLE_RATE_OVERRIDES"},
+			Usage:       "Export the metrics of only a fraction of the runs of high-volume repositories. Format <owner>/<repo>=<rate>,<owner>/<repo2>=<rate> (like test/test=0.1)",
+			Destination: &Metrics.SampleRateOverrides,
+		},
+		&cli.StringSliceFlag{
+			Name:        "label_value_allowlist",
+			EnvVars:     []string{"LABEL_VALUE_ALLOWLIST"},
+			Usage:       "Bound the cardinality of workflow run fields: values of a field matching none of its glob patterns are replaced with label_value_other. Format <field>=<pattern>,<pattern>,<field2>=<pattern> (like head_branch=main,develop,release/*)",
+			Destination: &Metrics.LabelValueAllowlist,
+		},
+		&cli.StringFlag{
+			Name:        "label_value_other",
+			EnvVars:     []string{"LABEL_VALUE_OTHER"},
+			Value:       "other",
+			Usage:       "Value replacing the field values left out of label_value_allowlist",
+			Destination: &Metrics.LabelValueOther,
+		},
+		&cli.StringFlag{
+			Name:        "empty_label_placeholder",
+			EnvVars:     []string{"EMPTY_LABEL_PLACEHOLDER"},
+			Usage:       "Value replacing the empty label values of the workflow run metrics (like none or n/a), empty values are kept when not set",
+			Destination: &Metrics.EmptyLabelPlaceholder,
+		},
+		&cli.StringSliceFlag{
+			Name:        "self_hosted_runner_labels",
+			EnvVars:     []string{"SELF_HOSTED_RUNNER_LABELS"},
+			Value:       cli.NewStringSlice("self-hosted"),
+			Usage:       "Jobs requesting any of these runner labels are classified as running on self-hosted runners",
+			Destination: &Metrics.SelfHostedRunnerLabels,
+		},
+		&cli.StringSliceFlag{
+			Name:        "failure_conclusions",
+			EnvVars:     []string{"FAILURE_CONCLUSIONS"},
+			Value:       cli.NewStringSlice("failure", "timed_out"),
+			Usage:       "Run conclusions counted as failures by github_workflow_run_failed, like failure,timed_out,cancelled",
+			Destination: &Metrics.FailureConclusions,
+		},
+		&cli.StringFlag{
+			Name:        "conclusion_buckets",
+			EnvVars:     []string{"CONCLUSION_BUCKETS"},
+			Usage:       "JSON object mapping run conclusions to the bucket exported as the conclusion_bucket field, like {\"neutral\":\"noop\",\"skipped\":\"noop\",\"cancelled\":\"aborted\",\"stale\":\"aborted\"}. Unmapped conclusions are exported as is",
+			Destination: &Metrics.ConclusionBuckets,
+		},
+		&cli.IntFlag{
+			Name:        "max_runs_per_cycle",
+			EnvVars:     []string{"MAX_RUNS_PER_CYCLE"},
+			Value:       0,
+			Usage:       "Maximum number of workflow runs exported per collection cycle across all repositories, shared fairly among them, to protect Prometheus from cardinality spikes. 0 means unlimited",
+			Destination: &Metrics.MaxRunsPerCycle,
+		},
+		&cli.StringFlag{
+			Name:        "dispatch_input_key",
+			EnvVars:     []string{"DISPATCH_INPUT_KEY"},
+			Usage:       "Name of the workflow_dispatch input (like environment) exported as the dispatch_input field, looked up as <key>=<value> in the run display title, then in its job names",
+			Destination: &Metrics.DispatchInputKey,
+		},
+		&cli.StringFlag{
+			Name:        "commit_title_mode",
+			EnvVars:     []string{"COMMIT_TITLE_MODE"},
+			Value:       "first_line",
+			Usage:       "Value of the derived_commit_pr_title field: first_line of the commit message or title, full_truncated message on a single line cut to commit_title_max_length characters, or none to always leave it empty",
+			Destination: &Metrics.CommitTitleMode,
+		},
+		&cli.IntFlag{
+			Name:        "commit_title_max_length",
+			EnvVars:     []string{"COMMIT_TITLE_MAX_LENGTH"},
+			Value:       100,
+			Usage:       "Maximum number of characters of derived_commit_pr_title when commit_title_mode is full_truncated, 0 for no limit",
+			Destination: &Metrics.CommitTitleMaxLength,
+		},
+		&cli.StringFlag{
+			Name:        "path_derived_label_regex",
+			EnvVars:     []string{"PATH_DERIVED_LABEL_REGEX"},
+			Usage:       "Regex applied to the workflow path of each run, whose named capture groups become labels of the workflow run metrics (like ^\\.github/workflows/(?P<service>[^-]+)-)",
+			Destination: &Metrics.PathDerivedLabelRegex,
+		},
+		&cli.BoolFlag{
+			Name:    "resolve_pr_from_commit",
+			EnvVars: []string{"RESOLVE_PR_FROM_COMMIT"},
+			Usage: "When true, will resolve pr_number and derived_commit_pr_title of push-triggered runs from the pull request " +
+				"associated with the head commit. Costs one API call per distinct head SHA (results are cached)",
+			Value:       false,
+			Destination: &Metrics.ResolvePRFromCommit,
+		},
+		&cli.BoolFlag{
+			Name:        "fetch_check_runs",
+			EnvVars:     []string{"FETCH_CHECK_RUNS"},
+			Value:       false,
+			Usage:       "When true, will fetch the check runs of the head commit of each workflow run (github_check_run_status). Costs one API call per distinct head SHA, cached once all its checks completed",
+			Destination: &Metrics.FetchCheckRuns,
+		},
+		&cli.BoolFlag{
+			Name:        "fetch_deployments",
+			EnvVars:     []string{"FETCH_DEPLOYMENTS"},
+			Usage:       "When true, will fetch the deployments of each repository (and the latest status of new ones) to count successful deployments",
+			Value:       false,
+			Destination: &Metrics.FetchDeployments,
+		},
+		&cli.BoolFlag{
+			Name:        "fetch_runners",
+			EnvVars:     []string{"FETCH_RUNNERS"},
+			Usage:       "When true, will fetch the self-hosted runners of the repositories, organizations and enterprise (requires admin access)",
+			Value:       false,
+			Destination: &Metrics.FetchRunners,
+		},
+		&cli.BoolFlag{
+			Name:        "fetch_cache_usage",
+			EnvVars:     []string{"FETCH_CACHE_USAGE"},
+			Usage:       "When true, will perform an API call per repository to fetch its GitHub Actions cache usage",
+			Value:       false,
+			Destination: &Metrics.FetchCacheUsage,
+		},
+		&cli.BoolFlag{
+			Name:        "fetch_repo_billing",
+			EnvVars:     []string{"FETCH_REPO_BILLING"},
+			Usage:       "When true, will perform an API call per workflow of each private repository to export its billable usage over the billing cycle",
+			Value:       false,
+			Destination: &Metrics.FetchRepoBilling,
+		},
+		&cli.BoolFlag{
+			Name:        "fetch_org_secrets_count",
+			EnvVars:     []string{"FETCH_ORG_SECRETS_COUNT"},
+			Usage:       "When true, will count the organization-level Actions secrets and variables of each organization (requires the admin:org scope)",
+			Value:       false,
+			Destination: &Metrics.FetchOrgSecretsCount,
+		},
+		&cli.BoolFlag{
+			Name:        "fetch_org_actions_permissions",
+			EnvVars:     []string{"FETCH_ORG_ACTIONS_PERMISSIONS"},
+			Usage:       "When true, will export the Actions permissions policy of each organization (requires the admin:org scope)",
+			Value:       false,
+			Destination: &Metrics.FetchOrgActionsPermissions,
+		},
+		&cli.BoolFlag{
+			Name:        "fetch_scale_sets",
+			EnvVars:     []string{"FETCH_SCALE_SETS"},
+			Usage:       "When true, will export the current and busy replicas of the runner groups of each organization, where Actions Runner Controller scale sets register their runners",
+			Value:       false,
+			Destination: &Metrics.FetchScaleSets,
+		},
+		&cli.Int64Flag{
+			Name:        "cache_usage_refresh",
+			EnvVars:     []string{"CACHE_USAGE_REFRESH"},
+			Usage:       "Refresh time of the GitHub Actions cache usage in sec",
+			Value:       900,
+			Destination: &Metrics.CacheUsageRefresh,
+		},
+		&cli.StringFlag{
+			Name:        "runner_status_value_map",
+			EnvVars:     []string{"RUNNER_STATUS_VALUE_MAP"},
+			Usage:       "JSON object mapping runner statuses to the value of the runner status metrics, like {\"online-idle\":1,\"online-busy\":2,\"offline\":0}. Online runners are looked up as online-idle or online-busy first, then online",
+			Destination: &Metrics.RunnerStatusValueMap,
+		},
+		&cli.Float64Flag{
+			Name:        "default_status_value",
+			EnvVars:     []string{"DEFAULT_STATUS_VALUE"},
+			Value:       99,
+			Usage:       "Value of github_workflow_run_status (and the other run status metrics) for statuses the exporter doesn't map",
+			Destination: &Metrics.DefaultStatusValue,
+		},
+		&cli.Float64Flag{
+			Name:        "failure_status_value",
+			EnvVars:     []string{"FAILURE_STATUS_VALUE"},
+			Value:       -1,
+			Usage:       "Value of github_workflow_run_status (and the other run status metrics) for failed runs. Set it to 0 for the former mapping",
+			Destination: &Metrics.FailureStatusValue,
+		},
 		&cli.Int64Flag{
 			Name:        "github_cache_size_bytes",
 			EnvVars:     []string{"GITHUB_CACHE_SIZE_BYTES"},
@@ -143,6 +614,14 @@ func InitConfiguration() []cli.Flag {
 				"This defines the maximum age of runs the exporter will attempt to fetch.",
 			Destination: &Github.FetchMaxWorkflowCreationAgeHours,
 		},
+		&cli.Int64Flag{
+			Name:    "fetch_recently_completed_hours",
+			EnvVars: []string{"FETCH_RECENTLY_COMPLETED_HOURS"},
+			Value:   0,
+			Usage: "Also fetch the workflow runs created before fetch_max_workflow_creation_age_hours but COMPLETED within this many hours, " +
+				"so long runs aren't missed. Widens the listed creation window by max_plausible_run_duration_hours. 0 disables it.",
+			Destination: &Github.FetchRecentlyCompletedHours,
+		},
 		&cli.Int64Flag{
 			Name:    "workflow_cache_refresh_interval_seconds",
 			EnvVars: []string{"WORKFLOW_CACHE_REFRESH_INTERVAL_SECONDS"},
@@ -150,5 +629,81 @@ func InitConfiguration() []cli.Flag {
 			Usage:   "How often in seconds to refresh the cache mapping workflow IDs to workflow names.",
 			Destination: &Github.WorkflowCacheRefreshIntervalSeconds,
 		},
+		&cli.BoolFlag{
+			Name:        "skip_repos_without_workflows",
+			EnvVars:     []string{"SKIP_REPOS_WITHOUT_WORKFLOWS"},
+			Usage:       "When true, repositories found to have no workflows are skipped when fetching workflow runs",
+			Value:       false,
+			Destination: &Github.SkipReposWithoutWorkflows,
+		},
+		&cli.BoolFlag{
+			Name:        "default_branch_only",
+			EnvVars:     []string{"DEFAULT_BRANCH_ONLY"},
+			Value:       false,
+			Usage:       "Only export workflow runs of the default branch of each repository. Costs one API call per explicitly configured repository per workflow cache refresh",
+			Destination: &Github.DefaultBranchOnly,
+		},
+		&cli.StringFlag{
+			Name:        "fetch_strategy",
+			EnvVars:     []string{"FETCH_STRATEGY"},
+			Value:       "repo",
+			Usage:       "How workflow runs are fetched: repo lists the runs of every repository, search locates the repositories containing search_workflow_files with the code search and lists the runs of these workflows only",
+			Destination: &Github.FetchStrategy,
+		},
+		&cli.StringSliceFlag{
+			Name:        "search_workflow_files",
+			EnvVars:     []string{"SEARCH_WORKFLOW_FILES"},
+			Usage:       "Workflow file names (like ci.yml,deploy.yml) whose runs are fetched when fetch_strategy is search",
+			Destination: &Github.SearchWorkflowFiles,
+		},
+		&cli.BoolFlag{
+			Name:        "prime_cache_on_startup",
+			EnvVars:     []string{"PRIME_CACHE_ON_STARTUP"},
+			Value:       false,
+			Usage:       "When true, the repositories and workflow definitions are fetched before serving metrics and starting the workflow run fetcher, so that workflow names resolve from the first cycle",
+			Destination: &Github.PrimeCacheOnStartup,
+		},
+		&cli.Int64Flag{
+			Name:        "prime_cache_timeout_seconds",
+			EnvVars:     []string{"PRIME_CACHE_TIMEOUT_SECONDS"},
+			Value:       300,
+			Usage:       "Maximum time in seconds waited for the fetch of prime_cache_on_startup, after which the exporter starts anyway",
+			Destination: &Github.PrimeCacheTimeoutSeconds,
+		},
+		&cli.Int64Flag{
+			Name:        "max_rate_limit_sleep_seconds",
+			EnvVars:     []string{"MAX_RATE_LIMIT_SLEEP_SECONDS"},
+			Value:       3600,
+			Usage:       "Maximum time in seconds a rate-limited fetcher pauses until the reset time of the limit, guarding against clock skew. 0 disables the cap",
+			Destination: &Github.MaxRateLimitSleepSeconds,
+		},
+		&cli.BoolFlag{
+			Name:        "report_blocked_repos",
+			EnvVars:     []string{"REPORT_BLOCKED_REPOS"},
+			Value:       false,
+			Usage:       "Export github_repo_actions_blocked for repositories whose workflows can't run (archived, disabled or all workflows disabled). Costs one API call per explicitly configured repository per workflow cache refresh",
+			Destination: &Github.ReportBlockedRepos,
+		},
+		&cli.Int64Flag{
+			Name:        "skip_dormant_workflow_days",
+			EnvVars:     []string{"SKIP_DORMANT_WORKFLOW_DAYS"},
+			Value:       0,
+			Usage:       "Poll the workflow runs of repositories whose workflows had no run, nor change, for this many days only hourly instead of every github_refresh. 0 polls every repository every cycle",
+			Destination: &Github.SkipDormantWorkflowDays,
+		},
+		&cli.IntFlag{
+			Name:        "max_cached_workflows",
+			EnvVars:     []string{"MAX_CACHED_WORKFLOWS"},
+			Value:       0,
+			Usage:       "Maximum number of workflow definitions kept in cache, evicting the least recently used repositories (fetched again on demand). 0 means unbounded",
+			Destination: &Github.MaxCachedWorkflows,
+		},
+		&cli.StringFlag{
+			Name:        "repo_visibility_filter",
+			EnvVars:     []string{"REPO_VISIBILITY_FILTER"},
+			Value:       "all",
+			Usage:       "Only monitor repositories with this visibility: all, public or private (internal repositories count as private). Costs one API call per explicitly configured repository per workflow cache refresh",
+			Destination: &Github.RepoVisibilityFilter,
+		},
 	}
 }
\ No newline at end of file
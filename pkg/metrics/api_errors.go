@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/bradleyfalzon/ghinstallation/v2"
+	"github.com/google/go-github/v72/github"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Categories of API errors, see classifyAPIError.
+const (
+	apiErrorRateLimit          = "rate_limit"
+	apiErrorSecondaryRateLimit = "secondary_rate_limit"
+	apiErrorAuth               = "auth"
+	apiErrorForbidden          = "forbidden"
+	apiErrorNotFound           = "not_found"
+	apiErrorClient             = "client_error"
+	apiErrorServer             = "server_error"
+	apiErrorTimeout            = "timeout"
+	apiErrorNetwork            = "network"
+	apiErrorOther              = "other"
+)
+
+var (
+	apiErrorsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "github_api_errors_total",
+			Help: "Number of failed GitHub API calls by endpoint and error category (rate_limit, auth, not_found, server_error, timeout, ...).",
+		},
+		[]string{"endpoint", "category"},
+	)
+)
+
+// classifyAPIError buckets an error returned by the GitHub client into a category.
+func classifyAPIError(err error) string {
+	var rateLimitErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	var responseErr *github.ErrorResponse
+	var installationErr *ghinstallation.HTTPError // GitHub App installation token refresh
+	var netErr net.Error
+	switch {
+	case errors.As(err, &rateLimitErr):
+		return apiErrorRateLimit
+	case errors.As(err, &abuseErr):
+		return apiErrorSecondaryRateLimit
+	case errors.As(err, &responseErr) && responseErr.Response != nil:
+		switch status := responseErr.Response.StatusCode; {
+		case status == http.StatusUnauthorized:
+			return apiErrorAuth
+		case status == http.StatusForbidden:
+			return apiErrorForbidden
+		case status == http.StatusNotFound:
+			return apiErrorNotFound
+		case status >= 500:
+			return apiErrorServer
+		default:
+			return apiErrorClient
+		}
+	case errors.As(err, &installationErr) && installationErr.Response != nil &&
+		installationErr.Response.StatusCode == http.StatusUnauthorized:
+		return apiErrorAuth
+	case errors.Is(err, context.DeadlineExceeded):
+		return apiErrorTimeout
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return apiErrorTimeout
+		}
+		return apiErrorNetwork
+	}
+	return apiErrorOther
+}
+
+// getAPIErrorEndpoint returns the endpoint label (see getAPIEndpoint) of the request that failed, if known.
+func getAPIErrorEndpoint(err error) string {
+	var rateLimitErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	var responseErr *github.ErrorResponse
+	var urlErr *url.Error
+	var resp *http.Response
+	switch {
+	case errors.As(err, &rateLimitErr):
+		resp = rateLimitErr.Response
+	case errors.As(err, &abuseErr):
+		resp = abuseErr.Response
+	case errors.As(err, &responseErr):
+		resp = responseErr.Response
+	case errors.As(err, &urlErr):
+		if parsed, parseErr := url.Parse(urlErr.URL); parseErr == nil {
+			return getAPIEndpoint(parsed.Path)
+		}
+	}
+	if resp != nil && resp.Request != nil && resp.Request.URL != nil {
+		return getAPIEndpoint(resp.Request.URL.Path)
+	}
+	return "unknown"
+}
+
+// recordAPIError counts a failed API call in github_api_errors_total. It does nothing when err is nil.
+func recordAPIError(err error) {
+	if err == nil {
+		return
+	}
+	category := classifyAPIError(err)
+	apiErrorsCounter.WithLabelValues(getAPIErrorEndpoint(err), category).Inc()
+	if category == apiErrorAuth {
+		noteAuthError()
+	}
+}
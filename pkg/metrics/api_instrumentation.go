@@ -0,0 +1,218 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v72/github"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	apiRequestDurationHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "github_api_request_duration_seconds",
+			Help:    "Duration of the requests sent to the GitHub API by endpoint. Responses served from the local cache are not observed.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"endpoint"},
+	)
+
+	apiLastFreshResponseGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_last_fresh_response_timestamp_seconds",
+			Help: "Unix timestamp of the last successful response of an endpoint carrying new content, " +
+				"as opposed to a response served from the local cache or revalidated by a 304 Not Modified.",
+		},
+		[]string{"endpoint"},
+	)
+
+	apiRateLimitRemainingGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_api_rate_limit_remaining",
+			Help: "Requests left in the core rate-limit budget of a GitHub App installation, from the X-RateLimit-Remaining header " +
+				"of its last response. installation_id is empty when authenticating with a token.",
+		},
+		[]string{"installation_id"},
+	)
+
+	apiClockSkewGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "github_api_clock_skew_seconds",
+			Help: "Difference between the local clock and the Date header of the last GitHub API response, positive when " +
+				"the exporter is ahead. Precise to about a second.",
+		},
+	)
+
+	apiPagesFetchedCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "github_api_pages_fetched_total",
+			Help: "Number of pages fetched by the pagination loops of the fetchers by endpoint, including pages served from the local cache.",
+		},
+		[]string{"endpoint"},
+	)
+
+	fetcherCacheHitRatioGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_fetcher_cache_hit_ratio",
+			Help: "Share of the GitHub API requests of a fetcher served by the local cache (X-From-Cache), including " +
+				"responses revalidated by a 304 Not Modified, since the exporter started.",
+		},
+		[]string{"fetcher"},
+	)
+
+	numericPathSegment = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// Values of the fetcher label of github_fetcher_cache_hit_ratio, passed through the request context.
+const (
+	fetcherRuns           = "runs"
+	fetcherJobs           = "jobs"
+	fetcherRunners        = "runners"
+	fetcherBilling        = "billing"
+	fetcherDeployments    = "deployments"
+	fetcherActionsCache   = "actions_cache"
+	fetcherOrgSecrets     = "org_secrets"
+	fetcherOrgPermissions = "org_permissions"
+	fetcherDiscovery      = "discovery" // Repositories, workflow definitions, code search and rate limits
+	fetcherOther          = "other"
+)
+
+type fetcherContextKey struct{}
+
+// fetcherContext returns the context of the API calls of a fetcher, which attributes their cache hits to it.
+func fetcherContext(fetcher string) context.Context {
+	return context.WithValue(context.Background(), fetcherContextKey{}, fetcher)
+}
+
+// getFetcher returns the fetcher of a request set by fetcherContext.
+func getFetcher(ctx context.Context) string {
+	if fetcher, ok := ctx.Value(fetcherContextKey{}).(string); ok {
+		return fetcher
+	}
+	return fetcherOther
+}
+
+// instrumentedTransport observes every request reaching the GitHub API. It is installed
+// below the caching transport, so only requests actually sent over the network are seen.
+// Each installation gets its own transport, which attributes rate-limit budgets to it.
+type instrumentedTransport struct {
+	next           http.RoundTripper
+	installationID string // Empty when not authenticating as a GitHub App installation
+}
+
+func newInstrumentedTransport(next http.RoundTripper, installationID string) *instrumentedTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &instrumentedTransport{next: next, installationID: installationID}
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	endpoint := getAPIEndpoint(req.URL.Path)
+	apiRequestDurationHistogram.WithLabelValues(endpoint).Observe(time.Since(start).Seconds())
+	// A 304 means the caching transport serves its stored copy (X-From-Cache), so only 2xx are fresh.
+	if err == nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		apiLastFreshResponseGauge.WithLabelValues(endpoint).SetToCurrentTime()
+	}
+	if err == nil && !strings.HasPrefix(endpoint, "/app/") { // App endpoints are authenticated by the app JWT, not the installation
+		t.recordRateLimit(resp)
+	}
+	if err == nil {
+		recordClockSkew(resp, start)
+	}
+	return resp, err
+}
+
+// recordClockSkew sets apiClockSkewGauge from the Date header of a response, compared to the middle of the request.
+// Rate-limit reset sleeps and the age metrics assume both clocks agree.
+func recordClockSkew(resp *http.Response, start time.Time) {
+	serverDate, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return
+	}
+	localDate := start.Add(time.Since(start) / 2)
+	apiClockSkewGauge.Set(localDate.Sub(serverDate).Seconds())
+}
+
+// recordRateLimit sets apiRateLimitRemainingGauge from the rate-limit headers of a response.
+// Other budgets (search, graphql, ...) are tracked separately by GitHub and ignored.
+func (t *instrumentedTransport) recordRateLimit(resp *http.Response) {
+	if resource := resp.Header.Get("X-RateLimit-Resource"); resource != "" && resource != "core" {
+		return
+	}
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err != nil { // Header missing, e.g. on GitHub Enterprise Server with rate limiting disabled
+		return
+	}
+	apiRateLimitRemainingGauge.WithLabelValues(t.installationID).Set(float64(remaining))
+}
+
+// recordPageFetched counts a page of a pagination loop in apiPagesFetchedCounter, by the endpoint of its request.
+func recordPageFetched(resp *github.Response) {
+	if resp == nil || resp.Response == nil || resp.Request == nil || resp.Request.URL == nil {
+		return
+	}
+	apiPagesFetchedCounter.WithLabelValues(getAPIEndpoint(resp.Request.URL.Path)).Inc()
+}
+
+// cacheObservingTransport counts the requests of each fetcher answered by the caching transport it wraps,
+// which marks the responses it serves with the X-From-Cache header.
+type cacheObservingTransport struct {
+	next http.RoundTripper
+
+	mu       sync.Mutex
+	requests map[string]int
+	hits     map[string]int
+}
+
+func newCacheObservingTransport(next http.RoundTripper) *cacheObservingTransport {
+	return &cacheObservingTransport{next: next, requests: make(map[string]int), hits: make(map[string]int)}
+}
+
+func (t *cacheObservingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	fetcher := getFetcher(req.Context())
+	t.mu.Lock()
+	t.requests[fetcher]++
+	if resp.Header.Get("X-From-Cache") != "" {
+		t.hits[fetcher]++
+	}
+	fetcherCacheHitRatioGauge.WithLabelValues(fetcher).Set(float64(t.hits[fetcher]) / float64(t.requests[fetcher]))
+	t.mu.Unlock()
+	return resp, err
+}
+
+// getAPIEndpoint turns a request path into a low-cardinality endpoint label by replacing
+// owners, repositories, organizations, enterprises, IDs and commit SHAs with placeholders,
+// e.g. /repos/{owner}/{repo}/actions/runs/{id}/timing.
+func getAPIEndpoint(path string) string {
+	path = strings.TrimPrefix(path, "/api/v3") // GitHub Enterprise Server
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := 0; i < len(segments); i++ {
+		switch {
+		case segments[i] == "repos" && i+2 < len(segments):
+			segments[i+1], segments[i+2] = "{owner}", "{repo}"
+			i += 2
+		case (segments[i] == "orgs" || segments[i] == "enterprises" || segments[i] == "users") && i+1 < len(segments):
+			segments[i+1] = "{" + strings.TrimSuffix(segments[i], "s") + "}"
+			i++
+		case segments[i] == "commits" && i+1 < len(segments):
+			segments[i+1] = "{sha}"
+			i++
+		case numericPathSegment.MatchString(segments[i]):
+			segments[i] = "{id}"
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/bradleyfalzon/ghinstallation/v2"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+)
+
+// getAppPrivateKeyFiles returns the private key files of the GitHub App: GITHUB_APP_PRIVATE_KEY first, then
+// the ones of GITHUB_APP_PRIVATE_KEYS, without duplicates.
+func getAppPrivateKeyFiles() []string {
+	seen := make(map[string]bool)
+	var keyFiles []string
+	for _, keyFile := range append([]string{config.Github.AppPrivateKey}, config.Github.AppPrivateKeys.Value()...) {
+		keyFile = strings.TrimSpace(keyFile)
+		if keyFile != "" && !seen[keyFile] {
+			seen[keyFile] = true
+			keyFiles = append(keyFiles, keyFile)
+		}
+	}
+	return keyFiles
+}
+
+// appKeyTransport authenticates as a GitHub App installation with several private keys of the App, to rotate
+// keys without downtime: while both keys are registered on the App, the next one is used when GitHub rejects
+// the current one for an installation token.
+type appKeyTransport struct {
+	transports []*ghinstallation.Transport
+	keyFiles   []string
+
+	mu      sync.Mutex
+	current int // Index of the key that got the last installation token
+}
+
+// newAppKeyTransport creates the installation transport of each private key file. apiURL is the GHE API base,
+// empty for github.com.
+func newAppKeyTransport(next http.RoundTripper, keyFiles []string, apiURL string) (*appKeyTransport, error) {
+	t := &appKeyTransport{keyFiles: keyFiles}
+	for _, keyFile := range keyFiles {
+		transport, err := ghinstallation.NewKeyFromFile(next, config.Github.AppID, config.Github.AppInstallationID, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("private key %s: %w", keyFile, err)
+		}
+		if apiURL != "" {
+			// The ghinstallation transport expects the GHE API base to correctly form token URLs.
+			transport.BaseURL = apiURL
+		}
+		t.transports = append(t.transports, transport)
+	}
+	return t, nil
+}
+
+// isAppKeyRejected reports whether an installation token refresh failed because GitHub rejected the JWT
+// signed with the private key, as opposed to a network error or an unknown installation.
+func isAppKeyRejected(err error) bool {
+	var httpErr *ghinstallation.HTTPError
+	return errors.As(err, &httpErr) && httpErr.Response != nil && httpErr.Response.StatusCode == http.StatusUnauthorized
+}
+
+// RoundTrip gets an installation token from the current key, or from the following ones when it is rejected,
+// before sending the request. The request itself is sent once, so its body never needs to be replayed.
+func (t *appKeyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	start := t.current
+	t.mu.Unlock()
+
+	var err error
+	for attempt := 0; attempt < len(t.transports); attempt++ {
+		i := (start + attempt) % len(t.transports)
+		if _, err = t.transports[i].Token(req.Context()); err != nil {
+			if isAppKeyRejected(err) && attempt+1 < len(t.transports) {
+				log.Printf("GitHub App private key %s was rejected, trying %s.", t.keyFiles[i], t.keyFiles[(i+1)%len(t.transports)])
+				continue
+			}
+			break
+		}
+		t.mu.Lock()
+		if t.current != i {
+			log.Printf("Authenticating with GitHub App private key %s.", t.keyFiles[i])
+			t.current = i
+		}
+		t.mu.Unlock()
+		return t.transports[i].RoundTrip(req)
+	}
+	if req.Body != nil {
+		req.Body.Close() // Per the RoundTripper contract, even on errors
+	}
+	return nil, err
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// A burst of authentication errors usually means the credentials were rotated: the GitHub client is
+// then rebuilt with NewClient, which re-reads GITHUB_TOKEN_FILE and the GitHub App private key file.
+const (
+	authErrorBurstThreshold = 5 // Authentication errors within authErrorBurstWindow triggering a rebuild
+	authErrorBurstWindow    = time.Minute
+	clientReloadCooldown    = 5 * time.Minute // Minimum time between two rebuilds
+)
+
+var (
+	clientReloadsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "github_client_reloads_total",
+			Help: "Number of times the GitHub client was rebuilt after a burst of authentication errors, by result (success or failure).",
+		},
+		[]string{"result"},
+	)
+
+	// Guards the authentication error burst tracking and the rebuild of the global client.
+	clientReloadMu     sync.Mutex
+	authErrorCount     int
+	authErrorBurstFrom time.Time
+	lastClientReload   time.Time
+
+	// Incremented on each successful rebuild, see getClientGeneration.
+	clientGeneration atomic.Uint64
+)
+
+// getClientGeneration returns a number that changes whenever the GitHub client is rebuilt,
+// letting fetchers detect that a cycle ran (partly) with revoked credentials.
+func getClientGeneration() uint64 {
+	return clientGeneration.Load()
+}
+
+// noteAuthError tracks authentication errors and rebuilds the GitHub client when
+// authErrorBurstThreshold of them happen within authErrorBurstWindow, at most once per clientReloadCooldown.
+func noteAuthError() {
+	clientReloadMu.Lock()
+	defer clientReloadMu.Unlock()
+
+	now := time.Now()
+	if now.Sub(authErrorBurstFrom) > authErrorBurstWindow {
+		authErrorBurstFrom = now
+		authErrorCount = 0
+	}
+	authErrorCount++
+	if authErrorCount < authErrorBurstThreshold || now.Sub(lastClientReload) < clientReloadCooldown {
+		return
+	}
+	lastClientReload = now
+	authErrorCount = 0
+
+	log.Printf("%d GitHub API authentication errors within %v. Rebuilding the GitHub client to pick up rotated credentials.",
+		authErrorBurstThreshold, authErrorBurstWindow)
+	newClient, err := NewClient()
+	if err != nil {
+		log.Printf("Error rebuilding the GitHub client, keeping the current one: %v", err)
+		clientReloadsCounter.WithLabelValues("failure").Inc()
+		return
+	}
+	currentClient.Store(newClient)
+	clientGeneration.Add(1)
+	clientReloadsCounter.WithLabelValues("success").Inc()
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v72/github"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+)
+
+// Values of COMMIT_TITLE_MODE, controlling the derived_commit_pr_title field.
+const (
+	commitTitleModeFirstLine     = "first_line"
+	commitTitleModeFullTruncated = "full_truncated"
+	commitTitleModeNone          = "none"
+)
+
+// validateCommitTitleMode checks COMMIT_TITLE_MODE.
+func validateCommitTitleMode(mode string) error {
+	switch mode {
+	case commitTitleModeFirstLine, commitTitleModeFullTruncated, commitTitleModeNone:
+		return nil
+	}
+	return fmt.Errorf("unknown mode %q, expected %s, %s or %s", mode, commitTitleModeFirstLine, commitTitleModeFullTruncated, commitTitleModeNone)
+}
+
+// deriveCommitPrTitle resolves the derived_commit_pr_title field of a run: the title of its pull request,
+// else its display title, else its head commit message. In full_truncated mode the head commit message
+// is preferred to the display title, which only holds its first line.
+func deriveCommitPrTitle(run *github.WorkflowRun) string {
+	mode := config.Metrics.CommitTitleMode
+	if mode == commitTitleModeNone {
+		return ""
+	}
+	if run.GetEvent() == "pull_request" && len(run.PullRequests) > 0 && run.PullRequests[0] != nil &&
+		run.PullRequests[0].Title != nil {
+		return formatCommitTitle(*run.PullRequests[0].Title)
+	}
+	if mode == commitTitleModeFullTruncated && run.GetHeadCommit().GetMessage() != "" {
+		return formatCommitTitle(run.GetHeadCommit().GetMessage())
+	}
+	if run.GetDisplayTitle() != "" { // Use DisplayTitle (v72) if available
+		return formatCommitTitle(run.GetDisplayTitle())
+	}
+	return formatCommitTitle(run.GetHeadCommit().GetMessage())
+}
+
+// formatCommitTitle shapes a title or commit message according to COMMIT_TITLE_MODE: its first line, or the
+// whole text on a single line cut to COMMIT_TITLE_MAX_LENGTH characters.
+func formatCommitTitle(title string) string {
+	switch config.Metrics.CommitTitleMode {
+	case commitTitleModeNone:
+		return ""
+	case commitTitleModeFullTruncated:
+		title = strings.Join(strings.Fields(title), " ")
+		if maxLength := config.Metrics.CommitTitleMaxLength; maxLength > 0 && len([]rune(title)) > maxLength {
+			title = strings.TrimSpace(string([]rune(title)[:maxLength]))
+		}
+		return title
+	}
+	firstLine, _, _ := strings.Cut(title, "\n")
+	return strings.TrimSpace(firstLine)
+}
@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Key: run conclusion, Value: bucket exported as the conclusion_bucket field (CONCLUSION_BUCKETS), set by InitMetrics.
+// Conclusions missing from the map are exported as is.
+var conclusionBuckets = map[string]string{}
+
+// parseConclusionBuckets parses a JSON object of conclusion to bucket name, like {"neutral":"noop","skipped":"noop"}.
+// An empty string maps no conclusion.
+func parseConclusionBuckets(bucketMap string) (map[string]string, error) {
+	if bucketMap == "" {
+		return map[string]string{}, nil
+	}
+	buckets := make(map[string]string)
+	if err := json.Unmarshal([]byte(bucketMap), &buckets); err != nil {
+		return nil, fmt.Errorf("not a JSON object of conclusion to bucket name: %w", err)
+	}
+	known := make(map[string]bool)
+	for _, conclusion := range knownConclusions {
+		known[conclusion] = true
+	}
+	for conclusion, bucket := range buckets {
+		if !known[conclusion] {
+			return nil, fmt.Errorf("unknown conclusion %q, expected one of %s", conclusion, strings.Join(knownConclusions, ", "))
+		}
+		if bucket == "" {
+			return nil, fmt.Errorf("empty bucket name for conclusion %q", conclusion)
+		}
+	}
+	return buckets, nil
+}
+
+// getConclusionBucket returns the bucket of a conclusion, the conclusion itself when it isn't mapped.
+// Runs not completed yet have no conclusion, hence no bucket.
+func getConclusionBucket(conclusion string) string {
+	if bucket, ok := conclusionBuckets[conclusion]; ok {
+		return bucket
+	}
+	return conclusion
+}
@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+)
+
+// getFetchConcurrency returns the maximum number of concurrent fetches (FETCH_CONCURRENCY, at least 1).
+func getFetchConcurrency() int {
+	if config.Github.FetchConcurrency < 1 {
+		return 1
+	}
+	return config.Github.FetchConcurrency
+}
+
+// fetchConcurrently calls fetch for each item from a bounded pool of goroutines and returns the results
+// by item. Rate limits are still handled by each fetch, which pauses its own goroutine until the reset.
+func fetchConcurrently[T any](items []string, fetch func(item string) T) map[string]T {
+	results := make(map[string]T, len(items))
+	var resultsMutex sync.Mutex
+
+	queue := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < getFetchConcurrency() && i < len(items); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range queue {
+				result := fetch(item)
+				resultsMutex.Lock()
+				results[item] = result
+				resultsMutex.Unlock()
+			}
+		}()
+	}
+
+	for _, item := range items {
+		queue <- item
+	}
+	close(queue)
+	wg.Wait()
+	return results
+}
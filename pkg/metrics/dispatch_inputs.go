@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v72/github"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+)
+
+// Matches "<DISPATCH_INPUT_KEY>=<value>" or "<DISPATCH_INPUT_KEY>: <value>", set by InitMetrics.
+var dispatchInputPattern *regexp.Regexp
+
+// compileDispatchInputPattern builds dispatchInputPattern from DISPATCH_INPUT_KEY. It returns nil when no key is configured.
+func compileDispatchInputPattern(key string) (*regexp.Regexp, error) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return nil, nil
+	}
+	pattern, err := regexp.Compile(`(?i)(?:^|[^\w-])` + regexp.QuoteMeta(key) + `\s*[=:]\s*([^\s,;()\[\]]+)`)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key %q: %w", key, err)
+	}
+	return pattern, nil
+}
+
+// findDispatchInput returns the value of the dispatch input in a text, or "" if it doesn't mention it.
+func findDispatchInput(text string) string {
+	if match := dispatchInputPattern.FindStringSubmatch(text); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// getDispatchInput resolves the value of the DISPATCH_INPUT_KEY input of a workflow_dispatch run.
+// The API doesn't return the inputs of a run, so it is looked up as "<key>=<value>" (or "<key>: <value>")
+// in the display title, set by the run-name of the workflow, then in the names of the jobs of the run,
+// which costs an API call when FETCH_WORKFLOW_JOBS is disabled. It returns "" for other events, or
+// when the input couldn't be found.
+func getDispatchInput(owner string, repoName string, run *github.WorkflowRun) string {
+	if dispatchInputPattern == nil || run.GetEvent() != "workflow_dispatch" {
+		return ""
+	}
+	if value := findDispatchInput(run.GetDisplayTitle()); value != "" {
+		return value
+	}
+	jobs, _ := getJobsForRun(owner, repoName, run) // Partial jobs may still carry the input
+	for _, job := range jobs {
+		if value := findDispatchInput(job.GetName()); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// isDispatchInputExported reports whether the dispatch_input field is part of the workflow run labels.
+func isDispatchInputExported() bool {
+	for _, fieldName := range workflowRunFieldNames {
+		if fieldName == "dispatch_input" {
+			return true
+		}
+	}
+	return false
+}
+
+// validateDispatchInputConfig checks that DISPATCH_INPUT_KEY is set when dispatch_input is exported.
+func validateDispatchInputConfig() error {
+	if isDispatchInputExported() && strings.TrimSpace(config.Metrics.DispatchInputKey) == "" {
+		return fmt.Errorf("field dispatch_input requires 'dispatch_input_key' (env: DISPATCH_INPUT_KEY)")
+	}
+	return nil
+}
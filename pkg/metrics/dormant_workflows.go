@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/google/go-github/v72/github"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+)
+
+// Dormant repositories, whose workflows all had no run for SKIP_DORMANT_WORKFLOW_DAYS, are polled at most this often.
+const dormantRepoPollInterval = time.Hour
+
+var (
+	// Key: "owner/repo", then workflow ID, Value: creation time of the latest run seen.
+	// Only accessed by the workflow run collector.
+	workflowLastActivity = make(map[string]map[int64]time.Time)
+
+	// Key: "owner/repo", Value: last time a dormant repository was polled anyway.
+	dormantRepoLastPoll = make(map[string]time.Time)
+
+	// Workflows can't be known to be dormant for longer than the exporter has been watching them.
+	dormancyTrackingStart = time.Now()
+)
+
+// recordWorkflowActivity remembers the latest run of each workflow of a repository.
+func recordWorkflowActivity(repoFullName string, runs []*github.WorkflowRun) {
+	for _, run := range runs {
+		if run == nil {
+			continue
+		}
+		if workflowLastActivity[repoFullName] == nil {
+			workflowLastActivity[repoFullName] = make(map[int64]time.Time)
+		}
+		createdAt := run.GetCreatedAt().Time
+		if createdAt.After(workflowLastActivity[repoFullName][run.GetWorkflowID()]) {
+			workflowLastActivity[repoFullName][run.GetWorkflowID()] = createdAt
+		}
+	}
+}
+
+// isRepoDormant reports whether none of the cached workflows of a repository was run or changed
+// within SKIP_DORMANT_WORKFLOW_DAYS. Repositories whose workflows aren't cached are never dormant.
+func isRepoDormant(repoFullName string) bool {
+	days := config.Github.SkipDormantWorkflowDays
+	if days <= 0 {
+		return false
+	}
+	cutoff := time.Now().AddDate(0, 0, -int(days))
+	if dormancyTrackingStart.After(cutoff) {
+		return false
+	}
+
+	workflowsMu.RLock()
+	defer workflowsMu.RUnlock()
+	repoWorkflows := workflows[repoFullName]
+	if len(repoWorkflows) == 0 {
+		return false
+	}
+	for workflowID, workflow := range repoWorkflows {
+		// A changed workflow file is likely to run soon
+		if workflow.GetUpdatedAt().After(cutoff) || workflowLastActivity[repoFullName][workflowID].After(cutoff) {
+			return false
+		}
+	}
+	return true
+}
+
+// shouldPollRepo reports whether the workflow runs of a repository are listed this cycle:
+// always for active repositories, at most every dormantRepoPollInterval for dormant ones.
+func shouldPollRepo(repoFullName string) bool {
+	if !isRepoDormant(repoFullName) {
+		delete(dormantRepoLastPoll, repoFullName)
+		return true
+	}
+	if time.Since(dormantRepoLastPoll[repoFullName]) < dormantRepoPollInterval {
+		return false
+	}
+	dormantRepoLastPoll[repoFullName] = time.Now()
+	return true
+}
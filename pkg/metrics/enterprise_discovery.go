@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"log"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+
+	"github.com/google/go-github/v72/github"
+)
+
+// maxDiscoveredOrganizations caps enterprise-wide organization discovery, so a very large
+// instance doesn't turn a refresh into an unbounded crawl.
+const maxDiscoveredOrganizations = 1000
+
+// Organizations found by the last successful enterprise discovery, reused when a discovery fails.
+var discoveredOrganizations []string
+
+// isGithubEnterpriseServer reports whether the exporter targets a GitHub Enterprise Server rather than github.com.
+func isGithubEnterpriseServer() bool {
+	return config.Github.APIURL != "" && config.Github.APIURL != "api.github.com"
+}
+
+// getAllEnterpriseOrganizations lists the organizations of the enterprise. On GitHub Enterprise Server this
+// is every organization of the instance; on github.com, where the REST API can't list the organizations of
+// an enterprise, the organizations the authenticated user belongs to. It reports false on error.
+func getAllEnterpriseOrganizations() ([]string, bool) {
+	if getClient() == nil {
+		log.Println("getAllEnterpriseOrganizations: GitHub client not initialized.")
+		return nil, false
+	}
+
+	var orgaNames []string
+	opt := &github.OrganizationsListOptions{ListOptions: github.ListOptions{PerPage: getPerPage()}}
+	listOpt := &github.ListOptions{PerPage: getPerPage()}
+	for len(orgaNames) < maxDiscoveredOrganizations {
+		var orgs []*github.Organization
+		var httpResp *github.Response
+		var err error
+		if isGithubEnterpriseServer() {
+			orgs, httpResp, err = getClient().Organizations.ListAll(fetcherContext(fetcherDiscovery), opt)
+		} else {
+			orgs, httpResp, err = getClient().Organizations.List(fetcherContext(fetcherDiscovery), "", listOpt)
+		}
+		recordAPIError(err)
+		if rlErr, ok := err.(*github.RateLimitError); ok {
+			log.Printf("Organizations listing ratelimited for enterprise %s. Pausing until %s", config.EnterpriseName, rlErr.Rate.Reset.Time.String())
+			sleepUntilRateLimitReset(rlErr.Rate.Reset.Time)
+			continue
+		} else if err != nil {
+			log.Printf("Organizations listing error for enterprise %s: %v", config.EnterpriseName, err)
+			return nil, false
+		}
+
+		for _, org := range orgs {
+			if org.GetLogin() != "" {
+				orgaNames = append(orgaNames, org.GetLogin())
+			}
+		}
+
+		if isGithubEnterpriseServer() {
+			// ListAll pages with the ID of the last organization seen rather than page numbers.
+			if len(orgs) == 0 {
+				return orgaNames, true
+			}
+			opt.Since = orgs[len(orgs)-1].GetID()
+		} else {
+			recordPageFetched(httpResp)
+			if getNextPage(httpResp) == 0 {
+				return orgaNames, true
+			}
+			listOpt.Page = getNextPage(httpResp)
+		}
+	}
+	log.Printf("getAllEnterpriseOrganizations: Stopping discovery at %d organizations for enterprise %s.", maxDiscoveredOrganizations, config.EnterpriseName)
+	return orgaNames[:maxDiscoveredOrganizations], true
+}
+
+// getOrganizationsToDiscover returns the configured organizations, plus the ones of the enterprise
+// when ENTERPRISE_DISCOVER_ALL_ORGS is enabled.
+func getOrganizationsToDiscover() []string {
+	organizations := config.Github.Organizations.Value()
+	if !config.Github.EnterpriseDiscoverAllOrgs || config.EnterpriseName == "" {
+		return organizations
+	}
+
+	if orgaNames, ok := getAllEnterpriseOrganizations(); ok {
+		discoveredOrganizations = orgaNames
+		log.Printf("periodicGithubFetcher: Discovered %d organization(s) in enterprise %s.", len(orgaNames), config.EnterpriseName)
+	} else {
+		log.Printf("periodicGithubFetcher: Using the %d organization(s) of the last enterprise discovery.", len(discoveredOrganizations))
+	}
+
+	seen := make(map[string]bool)
+	var merged []string
+	for _, orga := range append(append([]string{}, organizations...), discoveredOrganizations...) {
+		if orga != "" && !seen[orga] {
+			seen[orga] = true
+			merged = append(merged, orga)
+		}
+	}
+	return merged
+}
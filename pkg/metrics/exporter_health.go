@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Memory, GC and goroutine metrics (go_*, process_*) come from the collectors of the default registry.
+var (
+	fetcherGoroutinesGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "github_exporter_fetcher_goroutines",
+			Help: "Number of fetcher goroutines running. A fetcher whose feature is not configured stops right after starting.",
+		},
+	)
+
+	lastCycleReposGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "github_exporter_last_cycle_repositories",
+			Help: "Number of repositories whose workflow runs were fetched by the last workflow run collection cycle.",
+		},
+	)
+
+	exporterDegradedGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "github_exporter_degraded",
+			Help: "Set to 1 while the last cycle of a fetcher failed for every repository or organization, e.g. when GitHub is unreachable. " +
+				"Its metrics keep their last known values meanwhile.",
+		},
+	)
+
+	scrapeOverrunGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_scrape_overrun",
+			Help: "Set to 1 while the last collection cycle of a fetcher took longer than its refresh interval, so its metrics lag.",
+		},
+		[]string{"fetcher"},
+	)
+
+	scrapeOverrunSecondsGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_scrape_overrun_seconds",
+			Help: "Time by which the last collection cycle of a fetcher exceeded its refresh interval, 0 when it fit in it.",
+		},
+		[]string{"fetcher"},
+	)
+
+	// Key: fetcher, Value: whether its last cycle failed entirely. Guarded by degradedFetchersMu.
+	degradedFetchers   = make(map[string]bool)
+	degradedFetchersMu sync.Mutex
+)
+
+// setFetcherDegraded records whether the last cycle of a fetcher failed entirely, and sets exporterDegradedGauge
+// when any fetcher is degraded.
+func setFetcherDegraded(fetcher string, degraded bool) {
+	degradedFetchersMu.Lock()
+	defer degradedFetchersMu.Unlock()
+	if degraded != degradedFetchers[fetcher] {
+		if degraded {
+			log.Printf("%s: Every fetch of the cycle failed. Serving the last known metrics until GitHub is reachable again.", fetcher)
+		} else {
+			log.Printf("%s: Fetches succeed again, leaving degraded mode.", fetcher)
+		}
+	}
+	degradedFetchers[fetcher] = degraded
+	anyDegraded := 0.0
+	for _, isDegraded := range degradedFetchers {
+		if isDegraded {
+			anyDegraded = 1
+		}
+	}
+	exporterDegradedGauge.Set(anyDegraded)
+}
+
+// observeCycleDuration sets the overrun gauges of a fetcher from the duration of its last collection cycle.
+// It only reports: adjusting the refresh interval is left to AUTO_TUNE_REFRESH.
+func observeCycleDuration(fetcher string, cycleDuration time.Duration, refreshInterval time.Duration) {
+	recordCompletedCycle(fetcher)
+	overrun := cycleDuration - refreshInterval
+	if refreshInterval <= 0 || overrun <= 0 {
+		scrapeOverrunGauge.WithLabelValues(fetcher).Set(0)
+		scrapeOverrunSecondsGauge.WithLabelValues(fetcher).Set(0)
+		return
+	}
+	scrapeOverrunGauge.WithLabelValues(fetcher).Set(1)
+	scrapeOverrunSecondsGauge.WithLabelValues(fetcher).Set(overrun.Seconds())
+}
+
+// startFetcher runs a fetcher in its own goroutine, counted in fetcherGoroutinesGauge while it runs.
+func startFetcher(fetcher func()) {
+	fetcherGoroutinesGauge.Inc()
+	go func() {
+		defer fetcherGoroutinesGauge.Dec()
+		fetcher()
+	}()
+}
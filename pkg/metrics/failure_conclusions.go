@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v72/github"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Conclusions of a completed workflow run.
+var knownConclusions = []string{
+	"action_required", "cancelled", "failure", "neutral", "skipped", "stale", "startup_failure", "success", "timed_out",
+}
+
+// Conclusions counted as failures by github_workflow_run_failed (FAILURE_CONCLUSIONS), set by InitMetrics.
+var failureConclusions = map[string]bool{"failure": true, "timed_out": true}
+
+var workflowRunFailedGauge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "github_workflow_run_failed",
+		Help: "Set to 1 when the latest completed run of a workflow in the fetch window concluded with one of FAILURE_CONCLUSIONS, 0 otherwise.",
+	},
+	[]string{"repo", "workflow_name"},
+)
+
+// parseFailureConclusions parses FAILURE_CONCLUSIONS.
+func parseFailureConclusions(conclusions []string) (map[string]bool, error) {
+	known := make(map[string]bool)
+	for _, conclusion := range knownConclusions {
+		known[conclusion] = true
+	}
+	parsed := make(map[string]bool)
+	for _, conclusion := range conclusions {
+		conclusion = strings.TrimSpace(conclusion)
+		if conclusion == "" {
+			continue
+		}
+		if !known[conclusion] {
+			return nil, fmt.Errorf("unknown conclusion %q, expected one of %s", conclusion, strings.Join(knownConclusions, ", "))
+		}
+		parsed[conclusion] = true
+	}
+	if len(parsed) == 0 {
+		return nil, fmt.Errorf("no conclusion given")
+	}
+	return parsed, nil
+}
+
+// latestCompletedRunTracker keeps the most recent completed run of each workflow over a cycle.
+type latestCompletedRunTracker map[workflowKey]*github.WorkflowRun
+
+func (t latestCompletedRunTracker) add(repo string, workflowName string, run *github.WorkflowRun) {
+	if run.GetStatus() != "completed" {
+		return
+	}
+	key := workflowKey{repo, workflowName}
+	if latest := t[key]; latest == nil || run.GetCreatedAt().Time.After(latest.GetCreatedAt().Time) {
+		t[key] = run
+	}
+}
+
+// export sets workflowRunFailedGauge from the conclusion of the latest completed run of each workflow.
+func (t latestCompletedRunTracker) export() {
+	workflowRunFailedGauge.Reset()
+	for key, run := range t {
+		failed := 0.0
+		if failureConclusions[run.GetConclusion()] {
+			failed = 1
+		}
+		workflowRunFailedGauge.WithLabelValues(key.repo, key.workflowName).Set(failed)
+	}
+}
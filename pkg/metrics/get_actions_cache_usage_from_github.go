@@ -0,0 +1,125 @@
+package metrics
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+
+	"github.com/google/go-github/v72/github"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	actionsCacheSizeGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_actions_cache_size_bytes",
+			Help: "Total size in bytes of the active GitHub Actions caches of a repository.",
+		},
+		[]string{"repo"},
+	)
+
+	actionsCacheCountGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_actions_cache_count",
+			Help: "Number of active GitHub Actions caches of a repository.",
+		},
+		[]string{"repo"},
+	)
+
+	// Set once the cache usage endpoint returned 404 (GitHub Enterprise Server before 3.8), so it isn't called again.
+	actionsCacheUsageUnavailable bool
+)
+
+// getActionsCacheUsageForRepo fetches the cache usage of a repository. It returns nil on error.
+func getActionsCacheUsageForRepo(owner string, repoName string) *github.ActionsCacheUsage {
+	for {
+		cacheUsage, httpResp, err := getClient().Actions.GetCacheUsageForRepo(fetcherContext(fetcherActionsCache), owner, repoName)
+		recordAPIError(err)
+		if rlErr, ok := err.(*github.RateLimitError); ok {
+			log.Printf("GetCacheUsageForRepo ratelimited for %s/%s. Pausing until %s", owner, repoName, rlErr.Rate.Reset.Time.String())
+			sleepUntilRateLimitReset(rlErr.Rate.Reset.Time)
+			continue
+		} else if err != nil {
+			if httpResp != nil && httpResp.StatusCode == http.StatusNotFound && config.Github.APIURL != "" && config.Github.APIURL != "api.github.com" {
+				log.Printf("GetCacheUsageForRepo is not available on this GitHub Enterprise Server (%v). Disabling cache usage metrics.", err)
+				actionsCacheUsageUnavailable = true
+				return nil
+			}
+			log.Printf("GetCacheUsageForRepo error for %s/%s: %v", owner, repoName, err)
+			return nil
+		}
+		return cacheUsage
+	}
+}
+
+// getActionsCacheUsageFromGithub is the main goroutine for fetching Actions cache usage metrics.
+func getActionsCacheUsageFromGithub() {
+	if getClient() == nil {
+		log.Println("getActionsCacheUsageFromGithub: GitHub client not initialized.")
+		return
+	}
+
+	// Cache usage changes gradually, so it is refreshed on its own, slower cadence.
+	refreshInterval := time.Duration(config.Metrics.CacheUsageRefresh) * time.Second
+	if config.Metrics.CacheUsageRefresh <= 0 {
+		refreshInterval = 15 * time.Minute
+	}
+	log.Printf("getActionsCacheUsageFromGithub will refresh every %v", refreshInterval)
+	expectFetcherCycles("getActionsCacheUsageFromGithub")
+	sleepStartupJitter("getActionsCacheUsageFromGithub")
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		sleepTickJitter()
+		if isCollectionPaused() {
+			continue
+		}
+		cycleStart := time.Now()
+		collectActionsCacheUsage()
+		observeCycleDuration("getActionsCacheUsageFromGithub", time.Since(cycleStart), refreshInterval)
+	}
+}
+
+// collectActionsCacheUsage runs a single Actions cache usage collection cycle over all repositories.
+// When every repository fails, the gauges keep their last known values.
+func collectActionsCacheUsage() {
+	if len(repositories) == 0 || actionsCacheUsageUnavailable {
+		return
+	}
+	log.Printf("getActionsCacheUsageFromGithub: Starting cache usage collection cycle for %d repositories.", len(repositories))
+
+	cacheUsages := make(map[string]*github.ActionsCacheUsage)
+	attempted := false
+	for _, repoFullName := range repositories {
+		ownerAndRepo := strings.Split(repoFullName, "/")
+		if len(ownerAndRepo) != 2 {
+			log.Printf("getActionsCacheUsageFromGithub: Invalid repository format '%s'. Skipping.", repoFullName)
+			continue
+		}
+		attempted = true
+		if cacheUsage := getActionsCacheUsageForRepo(ownerAndRepo[0], ownerAndRepo[1]); cacheUsage != nil {
+			cacheUsages[repoFullName] = cacheUsage
+		}
+		if actionsCacheUsageUnavailable {
+			setFetcherDegraded("getActionsCacheUsageFromGithub", false) // Disabled rather than failing
+			return
+		}
+	}
+	degraded := attempted && len(cacheUsages) == 0
+	setFetcherDegraded("getActionsCacheUsageFromGithub", degraded)
+	if degraded {
+		return // Keep the last known cache usage
+	}
+
+	actionsCacheSizeGauge.Reset()
+	actionsCacheCountGauge.Reset()
+	for repoFullName, cacheUsage := range cacheUsages {
+		actionsCacheSizeGauge.WithLabelValues(repoFullName).Set(float64(cacheUsage.ActiveCachesSizeInBytes))
+		actionsCacheCountGauge.WithLabelValues(repoFullName).Set(float64(cacheUsage.ActiveCachesCount))
+	}
+	log.Println("getActionsCacheUsageFromGithub: Finished cache usage collection cycle.")
+}
@@ -1,13 +1,12 @@
 package metrics
 
 import (
-	"context"
 	"log"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/spendesk/github-actions-exporter/pkg/config" // Your config package
+	"github.com/markomanboi/github-actions-exporter/pkg/config" // Your config package
 
 	"github.com/google/go-github/v72/github" // <<< UPDATED to v72
 	"github.com/prometheus/client_golang/prometheus"
@@ -38,7 +37,7 @@ var (
 // This suggests the metric is per *workflow definition* and not per *workflow run*.
 // The labels "id", "node_id", "name", "state" refer to the *workflow definition*.
 func getBillableFromGithub() {
-	if client == nil {
+	if getClient() == nil {
 		log.Println("getBillableFromGithub: GitHub client not initialized.")
 		return
 	}
@@ -54,21 +53,27 @@ func getBillableFromGithub() {
 		refreshInterval = 300 * time.Second
 	}
 	log.Printf("getBillableFromGithub will refresh every %v", refreshInterval)
+	expectFetcherCycles("getBillableFromGithub")
 	ticker := time.NewTicker(refreshInterval)
 	defer ticker.Stop()
 
 	for range ticker.C {
-		if len(workflows) == 0 || len(repositories) == 0 {
+		if isCollectionPaused() {
+			continue
+		}
+		cachedWorkflows := getWorkflowsSnapshot()
+		if len(cachedWorkflows) == 0 || len(repositories) == 0 {
 			// log.Println("getBillableFromGithub: No workflows or repositories cached/configured. Skipping cycle.")
 			continue
 		}
 
 		log.Println("getBillableFromGithub: Starting billing collection cycle...")
+		cycleStart := time.Now()
 		// It's good practice to Reset if the set of things you're reporting on might change,
 		// or if some OS types might disappear for a workflow.
 		workflowBillGauge.Reset()
 
-		for repoFullName, repoWorkflowsMap := range workflows { // Iterate through cached workflows
+		for repoFullName, repoWorkflowsMap := range cachedWorkflows { // Iterate through cached workflows
 			if repoWorkflowsMap == nil {
 				continue
 			}
@@ -85,27 +90,8 @@ func getBillableFromGithub() {
 					continue
 				}
 
-				// API call is client.Actions.GetWorkflowUsageByID(ctx, owner, repo, workflowID)
-				// The original code had an inner loop for retries, which is good.
-				var usageData *github.WorkflowUsage
-				var errApi error
-				for i := 0; i < 3; i++ { // Retry loop for API call
-					usageData, _, errApi = client.Actions.GetWorkflowUsageByID(context.Background(), owner, repoName, workflowID)
-					if rlErr, ok := errApi.(*github.RateLimitError); ok {
-						log.Printf("GetWorkflowUsageByID ratelimited for workflow %d (%s/%s). Pausing until %s (attempt %d)", workflowID, owner, repoName, rlErr.Rate.Reset.Time.String(), i+1)
-						time.Sleep(time.Until(rlErr.Rate.Reset.Time))
-						continue // Retry API call
-					} else if errApi != nil {
-						log.Printf("GetWorkflowUsageByID error for workflow %d (%s/%s): %v (attempt %d)", workflowID, owner, repoName, errApi, i+1)
-						// Don't break immediately, allow retries. If all retries fail, usageData will be nil.
-					} else {
-						break // Success
-					}
-					time.Sleep(2 * time.Second) // Small delay before retrying non-rate-limit errors
-				}
-
-				if errApi != nil || usageData == nil { // If all retries failed or usageData is nil
-					log.Printf("Failed to get usage data for workflow %d (%s/%s) after retries.", workflowID, owner, repoName)
+				usageData := getCachedWorkflowUsage(owner, repoName, workflowID)
+				if usageData == nil {
 					continue // Skip to next workflow definition
 				}
 
@@ -132,9 +118,39 @@ func getBillableFromGithub() {
 			} // End loop through workflow definitions in a repo
 		} // End loop through repositories in the workflows cache
 		log.Println("getBillableFromGithub: Finished billing collection cycle.")
+		observeCycleDuration("getBillableFromGithub", time.Since(cycleStart), refreshInterval)
 	} // End ticker loop
 }
 
+// getWorkflowUsage fetches the billable usage of a workflow definition over the current billing cycle, with
+// retries. It returns nil when all attempts failed.
+func getWorkflowUsage(owner string, repoName string, workflowID int64) *github.WorkflowUsage {
+	// API call is getClient().Actions.GetWorkflowUsageByID(ctx, owner, repo, workflowID)
+	var usageData *github.WorkflowUsage
+	var errApi error
+	for i := 0; i < 3; i++ { // Retry loop for API call
+		usageData, _, errApi = getClient().Actions.GetWorkflowUsageByID(fetcherContext(fetcherBilling), owner, repoName, workflowID)
+		recordAPIError(errApi)
+		if rlErr, ok := errApi.(*github.RateLimitError); ok {
+			log.Printf("GetWorkflowUsageByID ratelimited for workflow %d (%s/%s). Pausing until %s (attempt %d)", workflowID, owner, repoName, rlErr.Rate.Reset.Time.String(), i+1)
+			sleepUntilRateLimitReset(rlErr.Rate.Reset.Time)
+			continue // Retry API call
+		} else if errApi != nil {
+			log.Printf("GetWorkflowUsageByID error for workflow %d (%s/%s): %v (attempt %d)", workflowID, owner, repoName, errApi, i+1)
+			// Don't break immediately, allow retries. If all retries fail, usageData will be nil.
+		} else {
+			break // Success
+		}
+		time.Sleep(2 * time.Second) // Small delay before retrying non-rate-limit errors
+	}
+
+	if errApi != nil || usageData == nil { // If all retries failed or usageData is nil
+		log.Printf("Failed to get usage data for workflow %d (%s/%s) after retries.", workflowID, owner, repoName)
+		return nil
+	}
+	return usageData
+}
+
 // getSafeInt64 helper (if not already present or imported from another file in the package)
 // func getSafeInt64(i *int64) int64 {
 // 	if i != nil {
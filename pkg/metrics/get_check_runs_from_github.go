@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"log"
+
+	"github.com/google/go-github/v72/github"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	checkRunStatusGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_check_run_status",
+			Help: "Status of the check runs (GitHub Actions jobs and third-party CI) of the head commits of the runs in the fetch window, " +
+				"with the same values as github_workflow_run_status.",
+		},
+		[]string{"repo", "head_sha", "check_name", "conclusion"},
+	)
+
+	// Key: "owner/repo@sha", Value: the latest check runs of the commit.
+	// Only commits whose check runs all completed are cached, as they no longer change.
+	// Entries for commits that are no longer in the fetch window are dropped by checkRunsCollector.export.
+	commitCheckRunsCache = make(map[string][]*github.CheckRun)
+)
+
+// getCheckRunsForCommit lists the latest check runs of a commit. It returns false when the list is incomplete.
+func getCheckRunsForCommit(owner string, repoName string, sha string) ([]*github.CheckRun, bool) {
+	cacheKey := owner + "/" + repoName + "@" + sha
+	if checkRuns, ok := commitCheckRunsCache[cacheKey]; ok {
+		return checkRuns, true
+	}
+
+	opt := &github.ListCheckRunsOptions{
+		Filter:      github.Ptr("latest"),
+		ListOptions: github.ListOptions{PerPage: getPerPage()},
+	}
+	var allCheckRuns []*github.CheckRun
+	for {
+		results, resp, err := getClient().Checks.ListCheckRunsForRef(fetcherContext(fetcherRuns), owner, repoName, sha, opt)
+		recordAPIError(err)
+		if rlErr, ok := err.(*github.RateLimitError); ok {
+			log.Printf("ListCheckRunsForRef ratelimited for %s (%s/%s). Pausing until %s", sha, owner, repoName, rlErr.Rate.Reset.Time.String())
+			sleepUntilRateLimitReset(rlErr.Rate.Reset.Time)
+			continue
+		} else if err != nil {
+			log.Printf("ListCheckRunsForRef error for %s (%s/%s): %v", sha, owner, repoName, err)
+			return allCheckRuns, false
+		}
+		if results != nil {
+			allCheckRuns = append(allCheckRuns, results.CheckRuns...)
+		}
+		recordPageFetched(resp)
+		if getNextPage(resp) == 0 {
+			break
+		}
+		opt.Page = getNextPage(resp)
+	}
+
+	allCompleted := true
+	for _, checkRun := range allCheckRuns {
+		if checkRun.GetStatus() != "completed" {
+			allCompleted = false
+			break
+		}
+	}
+	if allCompleted {
+		commitCheckRunsCache[cacheKey] = allCheckRuns
+	}
+	return allCheckRuns, true
+}
+
+type commitKey struct {
+	repo string
+	sha  string
+}
+
+// checkRunsCollector gathers the check runs of the head commits seen over a cycle.
+type checkRunsCollector map[commitKey][]*github.CheckRun
+
+// add fetches the check runs of a commit, once per cycle. Commits whose check runs couldn't be listed are skipped.
+func (c checkRunsCollector) add(owner string, repoName string, repoFullName string, sha string) {
+	if sha == "" {
+		return
+	}
+	key := commitKey{repoFullName, sha}
+	if _, ok := c[key]; ok {
+		return
+	}
+	if checkRuns, complete := getCheckRunsForCommit(owner, repoName, sha); complete {
+		c[key] = checkRuns
+	}
+}
+
+// export sets checkRunStatusGauge from the check runs of the cycle, and drops the cached check runs of other commits.
+func (c checkRunsCollector) export() {
+	checkRunStatusGauge.Reset()
+	seenCacheKeys := make(map[string]bool)
+	for key, checkRuns := range c {
+		seenCacheKeys[key.repo+"@"+key.sha] = true
+		for _, checkRun := range checkRuns {
+			if checkRun == nil {
+				continue
+			}
+			checkRunStatusGauge.WithLabelValues(key.repo, key.sha, checkRun.GetName(), checkRun.GetConclusion()).
+				Set(getNumericStatus(checkRun.GetStatus(), checkRun.GetConclusion()))
+		}
+	}
+	for cacheKey := range commitCheckRunsCache {
+		if !seenCacheKeys[cacheKey] {
+			delete(commitCheckRunsCache, cacheKey)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package metrics
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+
+	"github.com/google/go-github/v72/github"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	deploymentSuccessCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "github_deployment_success_total",
+			Help: "Number of successful deployments per environment, counted once per deployment when its latest status is success.",
+		},
+		[]string{"repo", "environment"},
+	)
+
+	// Deployments whose latest status reached a terminal state. Bounded by the fetch window.
+	seenDeployments = make(seenSet)
+)
+
+// getRecentDeploymentsForRepo fetches the deployments of a repository created after windowStart.
+// Deployments are listed newest first, so pagination stops at the first older one. On error, it returns
+// the deployments of the pages fetched so far and false.
+func getRecentDeploymentsForRepo(owner string, repoName string, windowStart time.Time) ([]*github.Deployment, bool) {
+	if getClient() == nil {
+		log.Println("getRecentDeploymentsForRepo: GitHub client not initialized.")
+		return nil, false
+	}
+
+	var recentDeployments []*github.Deployment
+	opt := &github.DeploymentsListOptions{ListOptions: github.ListOptions{PerPage: getPerPage()}}
+
+	for {
+		deploymentsPage, httpResp, err := getClient().Repositories.ListDeployments(fetcherContext(fetcherDeployments), owner, repoName, opt)
+		recordAPIError(err)
+		if rlErr, ok := err.(*github.RateLimitError); ok {
+			log.Printf("ListDeployments ratelimited for %s/%s. Pausing until %s", owner, repoName, rlErr.Rate.Reset.Time.String())
+			sleepUntilRateLimitReset(rlErr.Rate.Reset.Time)
+			continue
+		} else if err != nil {
+			log.Printf("ListDeployments error for repo %s/%s: %v", owner, repoName, err)
+			return recentDeployments, false
+		}
+
+		for _, deployment := range deploymentsPage {
+			if deployment == nil || deployment.ID == nil {
+				continue
+			}
+			if deployment.GetCreatedAt().Time.Before(windowStart) {
+				return recentDeployments, true
+			}
+			recentDeployments = append(recentDeployments, deployment)
+		}
+
+		recordPageFetched(httpResp)
+		if getNextPage(httpResp) == 0 {
+			break
+		}
+		opt.Page = getNextPage(httpResp)
+	}
+	return recentDeployments, true
+}
+
+// getLatestDeploymentState returns the state of the most recent status of a deployment ("" if it has none).
+func getLatestDeploymentState(owner string, repoName string, deploymentID int64) string {
+	for {
+		statuses, _, err := getClient().Repositories.ListDeploymentStatuses(fetcherContext(fetcherDeployments), owner, repoName, deploymentID, &github.ListOptions{PerPage: 1})
+		recordAPIError(err)
+		if rlErr, ok := err.(*github.RateLimitError); ok {
+			log.Printf("ListDeploymentStatuses ratelimited for deployment %d (%s/%s). Pausing until %s", deploymentID, owner, repoName, rlErr.Rate.Reset.Time.String())
+			sleepUntilRateLimitReset(rlErr.Rate.Reset.Time)
+			continue
+		} else if err != nil {
+			log.Printf("ListDeploymentStatuses error for deployment %d (%s/%s): %v", deploymentID, owner, repoName, err)
+			return ""
+		}
+		if len(statuses) == 0 || statuses[0] == nil {
+			return ""
+		}
+		return statuses[0].GetState()
+	}
+}
+
+// isTerminalDeploymentState reports whether a deployment status can no longer change to success.
+func isTerminalDeploymentState(state string) bool {
+	switch state {
+	case "success", "failure", "error", "inactive":
+		return true
+	}
+	return false
+}
+
+// getDeploymentsFromGithub is the main goroutine for fetching deployment metrics.
+func getDeploymentsFromGithub() {
+	if getClient() == nil {
+		log.Println("getDeploymentsFromGithub: GitHub client not initialized.")
+		return
+	}
+
+	refreshInterval := time.Duration(config.Github.Refresh) * time.Second
+	if config.Github.Refresh <= 0 {
+		refreshInterval = 60 * time.Second
+	}
+	log.Printf("getDeploymentsFromGithub will refresh every %v", refreshInterval)
+	expectFetcherCycles("getDeploymentsFromGithub")
+	sleepStartupJitter("getDeploymentsFromGithub")
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		sleepTickJitter()
+		if isCollectionPaused() {
+			continue
+		}
+		cycleStart := time.Now()
+		collectDeployments()
+		observeCycleDuration("getDeploymentsFromGithub", time.Since(cycleStart), refreshInterval)
+	}
+}
+
+// collectDeployments runs a single deployment collection cycle over all repositories. The counters keep
+// their values when every repository fails, the fetcher is flagged as degraded.
+func collectDeployments() {
+	if len(repositories) == 0 {
+		return
+	}
+	log.Printf("getDeploymentsFromGithub: Starting deployment collection cycle for %d repositories.", len(repositories))
+	windowStart := getFetchWindowStart()
+
+	attempted, anyFetched := false, false
+	for _, repoFullName := range repositories {
+		ownerAndRepo := strings.Split(repoFullName, "/")
+		if len(ownerAndRepo) != 2 {
+			log.Printf("getDeploymentsFromGithub: Invalid repository format '%s'. Skipping.", repoFullName)
+			continue
+		}
+		owner, repoName := ownerAndRepo[0], ownerAndRepo[1]
+
+		deployments, ok := getRecentDeploymentsForRepo(owner, repoName, windowStart)
+		attempted = true
+		anyFetched = anyFetched || ok
+		for _, deployment := range deployments {
+			if seenDeployments.has(deployment.GetID()) {
+				continue
+			}
+			state := getLatestDeploymentState(owner, repoName, deployment.GetID())
+			if !isTerminalDeploymentState(state) {
+				continue // Checked again next cycle
+			}
+			seenDeployments.add(deployment.GetID(), deployment.GetCreatedAt().Time)
+			if state == "success" {
+				deploymentSuccessCounter.WithLabelValues(repoFullName, deployment.GetEnvironment()).Inc()
+			}
+		}
+	}
+
+	setFetcherDegraded("getDeploymentsFromGithub", attempted && !anyFetched)
+	seenDeployments.prune(windowStart)
+	log.Println("getDeploymentsFromGithub: Finished deployment collection cycle.")
+}
@@ -0,0 +1,147 @@
+package metrics
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+
+	"github.com/google/go-github/v72/github"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	orgActionsPermissionsGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_org_actions_permissions",
+			Help: "GitHub Actions policy of an organization (always 1): repositories allowed to run workflows, and actions allowed in them.",
+		},
+		[]string{"org", "enabled_repositories", "allowed_actions"},
+	)
+
+	orgActionsSelectedActionsGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_org_actions_selected_actions_patterns",
+			Help: "Number of action patterns allowed by an organization whose allowed_actions policy is selected, " +
+				"labelled by whether actions created by GitHub and by verified creators are allowed too.",
+		},
+		[]string{"org", "github_owned_allowed", "verified_allowed"},
+	)
+)
+
+// getOrgActionsPolicy fetches the Actions permissions of an organization, and the actions it allows when its
+// policy is selected (nil otherwise). It returns false on error, logging when the token lacks the admin scope.
+func getOrgActionsPolicy(orgaName string) (*github.ActionsPermissions, *github.ActionsAllowed, bool) {
+	var permissions *github.ActionsPermissions
+	for permissions == nil {
+		var httpResp *github.Response
+		var err error
+		permissions, httpResp, err = getClient().Actions.GetActionsPermissions(fetcherContext(fetcherOrgPermissions), orgaName)
+		recordAPIError(err)
+		if rlErr, ok := err.(*github.RateLimitError); ok {
+			log.Printf("GetActionsPermissions ratelimited for org %s. Pausing until %s", orgaName, rlErr.Rate.Reset.Time.String())
+			sleepUntilRateLimitReset(rlErr.Rate.Reset.Time)
+			continue
+		} else if err != nil {
+			logOrgPermissionsError("GetActionsPermissions", orgaName, httpResp, err)
+			return nil, nil, false
+		} else if permissions == nil {
+			return nil, nil, false
+		}
+	}
+	if permissions.GetAllowedActions() != "selected" {
+		return permissions, nil, true
+	}
+
+	for {
+		allowed, httpResp, err := getClient().Actions.GetActionsAllowed(fetcherContext(fetcherOrgPermissions), orgaName)
+		recordAPIError(err)
+		if rlErr, ok := err.(*github.RateLimitError); ok {
+			log.Printf("GetActionsAllowed ratelimited for org %s. Pausing until %s", orgaName, rlErr.Rate.Reset.Time.String())
+			sleepUntilRateLimitReset(rlErr.Rate.Reset.Time)
+			continue
+		} else if err != nil {
+			logOrgPermissionsError("GetActionsAllowed", orgaName, httpResp, err)
+			return permissions, nil, true // The policy itself is known
+		}
+		return permissions, allowed, true
+	}
+}
+
+func logOrgPermissionsError(call string, orgaName string, httpResp *github.Response, err error) {
+	if httpResp != nil && (httpResp.StatusCode == http.StatusForbidden || httpResp.StatusCode == http.StatusNotFound) {
+		log.Printf("%s is not permitted for org %s: the token needs the admin:org scope, or the GitHub App "+
+			"the organization administration read permission (%v)", call, orgaName, err)
+		return
+	}
+	log.Printf("%s error for org %s: %v", call, orgaName, err)
+}
+
+// getOrgActionsPermissionsFromGithub is the main goroutine for fetching the Actions policies of the organizations.
+func getOrgActionsPermissionsFromGithub() {
+	if getClient() == nil {
+		log.Println("getOrgActionsPermissionsFromGithub: GitHub client not initialized.")
+		return
+	}
+	if len(config.Github.Organizations.Value()) == 0 {
+		log.Println("getOrgActionsPermissionsFromGithub: No organizations configured. Skipping organization Actions permissions collection.")
+		return
+	}
+
+	// Policies change rarely, so they are refreshed on a slower cadence.
+	refreshInterval := time.Duration(config.Github.Refresh) * 5 * time.Second
+	if config.Github.Refresh <= 0 {
+		refreshInterval = 300 * time.Second
+	}
+	log.Printf("getOrgActionsPermissionsFromGithub will refresh every %v", refreshInterval)
+	expectFetcherCycles("getOrgActionsPermissionsFromGithub")
+	sleepStartupJitter("getOrgActionsPermissionsFromGithub")
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		sleepTickJitter()
+		if isCollectionPaused() {
+			continue
+		}
+		cycleStart := time.Now()
+		collectOrgActionsPermissions()
+		observeCycleDuration("getOrgActionsPermissionsFromGithub", time.Since(cycleStart), refreshInterval)
+	}
+}
+
+// collectOrgActionsPermissions runs a single collection cycle of the organization Actions policies.
+func collectOrgActionsPermissions() {
+	type orgPolicy struct {
+		permissions *github.ActionsPermissions
+		allowed     *github.ActionsAllowed
+	}
+	policies := make(map[string]orgPolicy)
+	for _, orgaName := range config.Github.Organizations.Value() {
+		if orgaName == "" {
+			continue
+		}
+		if permissions, allowed, ok := getOrgActionsPolicy(orgaName); ok {
+			policies[orgaName] = orgPolicy{permissions, allowed}
+		}
+	}
+
+	orgActionsPermissionsGauge.Reset()
+	orgActionsSelectedActionsGauge.Reset()
+	for orgaName, policy := range policies {
+		orgActionsPermissionsGauge.WithLabelValues(
+			orgaName,
+			policy.permissions.GetEnabledRepositories(),
+			policy.permissions.GetAllowedActions(),
+		).Set(1)
+		if policy.allowed != nil {
+			orgActionsSelectedActionsGauge.WithLabelValues(
+				orgaName,
+				strconv.FormatBool(policy.allowed.GetGithubOwnedAllowed()),
+				strconv.FormatBool(policy.allowed.GetVerifiedAllowed()),
+			).Set(float64(len(policy.allowed.PatternsAllowed)))
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package metrics
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+
+	"github.com/google/go-github/v72/github"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	orgActionsSecretsCountGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_org_actions_secrets_count",
+			Help: "Number of organization-level GitHub Actions secrets.",
+		},
+		[]string{"org"},
+	)
+
+	orgActionsVariablesCountGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_org_actions_variables_count",
+			Help: "Number of organization-level GitHub Actions variables.",
+		},
+		[]string{"org"},
+	)
+)
+
+// getOrgActionsCount returns the total count of a list endpoint of an organization (secrets or variables),
+// read from a single page of one item. It returns false on error.
+func getOrgActionsCount(orgaName string, kind string, list func(ctx context.Context, org string, opts *github.ListOptions) (int, *github.Response, error)) (int, bool) {
+	for {
+		count, httpResp, err := list(fetcherContext(fetcherOrgSecrets), orgaName, &github.ListOptions{PerPage: 1})
+		recordAPIError(err)
+		if rlErr, ok := err.(*github.RateLimitError); ok {
+			log.Printf("Listing the %s of org %s ratelimited. Pausing until %s", kind, orgaName, rlErr.Rate.Reset.Time.String())
+			sleepUntilRateLimitReset(rlErr.Rate.Reset.Time)
+			continue
+		} else if err != nil {
+			if httpResp != nil && (httpResp.StatusCode == http.StatusForbidden || httpResp.StatusCode == http.StatusNotFound) {
+				log.Printf("Listing the %s of org %s is not permitted: the token needs the admin:org scope, or the GitHub App "+
+					"the organization %s read permission (%v)", kind, orgaName, kind, err)
+				return 0, false
+			}
+			log.Printf("Listing the %s of org %s failed: %v", kind, orgaName, err)
+			return 0, false
+		}
+		return count, true
+	}
+}
+
+func listOrgSecretsCount(ctx context.Context, org string, opts *github.ListOptions) (int, *github.Response, error) {
+	secrets, resp, err := getClient().Actions.ListOrgSecrets(ctx, org, opts)
+	if secrets == nil {
+		return 0, resp, err
+	}
+	return secrets.TotalCount, resp, err
+}
+
+func listOrgVariablesCount(ctx context.Context, org string, opts *github.ListOptions) (int, *github.Response, error) {
+	variables, resp, err := getClient().Actions.ListOrgVariables(ctx, org, opts)
+	if variables == nil {
+		return 0, resp, err
+	}
+	return variables.TotalCount, resp, err
+}
+
+// getOrgSecretsCountFromGithub is the main goroutine for fetching the count of organization secrets and variables.
+func getOrgSecretsCountFromGithub() {
+	if getClient() == nil {
+		log.Println("getOrgSecretsCountFromGithub: GitHub client not initialized.")
+		return
+	}
+	if len(config.Github.Organizations.Value()) == 0 {
+		log.Println("getOrgSecretsCountFromGithub: No organizations configured. Skipping organization secrets collection.")
+		return
+	}
+
+	// Secrets and variables change rarely, so they are refreshed on a slower cadence.
+	refreshInterval := time.Duration(config.Github.Refresh) * 5 * time.Second
+	if config.Github.Refresh <= 0 {
+		refreshInterval = 300 * time.Second
+	}
+	log.Printf("getOrgSecretsCountFromGithub will refresh every %v", refreshInterval)
+	expectFetcherCycles("getOrgSecretsCountFromGithub")
+	sleepStartupJitter("getOrgSecretsCountFromGithub")
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		sleepTickJitter()
+		if isCollectionPaused() {
+			continue
+		}
+		cycleStart := time.Now()
+		collectOrgSecretsCount()
+		observeCycleDuration("getOrgSecretsCountFromGithub", time.Since(cycleStart), refreshInterval)
+	}
+}
+
+// collectOrgSecretsCount runs a single collection cycle of the organization secrets and variables counts.
+func collectOrgSecretsCount() {
+	secretsCounts := make(map[string]int)
+	variablesCounts := make(map[string]int)
+	for _, orgaName := range config.Github.Organizations.Value() {
+		if orgaName == "" {
+			continue
+		}
+		if count, ok := getOrgActionsCount(orgaName, "secrets", listOrgSecretsCount); ok {
+			secretsCounts[orgaName] = count
+		}
+		if count, ok := getOrgActionsCount(orgaName, "variables", listOrgVariablesCount); ok {
+			variablesCounts[orgaName] = count
+		}
+	}
+
+	orgActionsSecretsCountGauge.Reset()
+	for orgaName, count := range secretsCounts {
+		orgActionsSecretsCountGauge.WithLabelValues(orgaName).Set(float64(count))
+	}
+	orgActionsVariablesCountGauge.Reset()
+	for orgaName, count := range variablesCounts {
+		orgActionsVariablesCountGauge.WithLabelValues(orgaName).Set(float64(count))
+	}
+}
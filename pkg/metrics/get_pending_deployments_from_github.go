@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"log"
+	"time"
+
+	"github.com/google/go-github/v72/github"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	workflowRunWaitingGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_workflow_run_waiting_seconds",
+			Help: "Time in seconds the longest-waiting run of a workflow has been waiting for a deployment approval on an environment.",
+		},
+		[]string{"repo", "workflow_name", "environment"},
+	)
+)
+
+type workflowRunWaitingKey struct {
+	repo         string
+	workflowName string
+	environment  string
+}
+
+// getPendingDeploymentsForRun fetches the environments a waiting run is blocked on.
+func getPendingDeploymentsForRun(owner string, repoName string, runID int64) []*github.PendingDeployment {
+	if getClient() == nil {
+		log.Println("getPendingDeploymentsForRun: GitHub client not initialized.")
+		return nil
+	}
+
+	for {
+		pendingDeployments, _, err := getClient().Actions.GetPendingDeployments(fetcherContext(fetcherRuns), owner, repoName, runID)
+		recordAPIError(err)
+		if rlErr, ok := err.(*github.RateLimitError); ok {
+			log.Printf("GetPendingDeployments ratelimited for run %d (%s/%s). Pausing until %s", runID, owner, repoName, rlErr.Rate.Reset.Time.String())
+			sleepUntilRateLimitReset(rlErr.Rate.Reset.Time)
+			continue
+		} else if err != nil {
+			log.Printf("GetPendingDeployments error for run %d (%s/%s): %v", runID, owner, repoName, err)
+			return nil
+		}
+		return pendingDeployments
+	}
+}
+
+// getRunWaitingSeconds returns, per environment, how long a waiting run has been waiting for approval.
+// The wait timer start is used when GitHub reports it, otherwise the run's last update
+// (which is when it entered the waiting state).
+func getRunWaitingSeconds(owner string, repoName string, run *github.WorkflowRun) map[string]float64 {
+	waiting := make(map[string]float64)
+	for _, pending := range getPendingDeploymentsForRun(owner, repoName, run.GetID()) {
+		if pending == nil || pending.Environment == nil {
+			continue
+		}
+		waitingSince := run.GetUpdatedAt().Time
+		if pending.WaitTimerStartedAt != nil && !pending.WaitTimerStartedAt.IsZero() {
+			waitingSince = pending.WaitTimerStartedAt.Time
+		}
+		if waitingSince.IsZero() {
+			continue
+		}
+		waiting[pending.Environment.GetName()] = time.Since(waitingSince).Seconds()
+	}
+	return waiting
+}
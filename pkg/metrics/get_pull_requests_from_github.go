@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"log"
+
+	"github.com/google/go-github/v72/github"
+)
+
+var (
+	// Key: head SHA, Value: the pull request associated with that commit (nil when there is none).
+	// Entries for SHAs that are no longer in the fetch window are dropped by pruneCommitPullRequestCache.
+	commitPullRequestCache = make(map[string]*github.PullRequest)
+)
+
+// getPullRequestForCommit returns the pull request a commit belongs to, preferring a merged one.
+// Results, including "no pull request", are cached by SHA since they are stable once a commit is pushed.
+func getPullRequestForCommit(owner string, repoName string, sha string) *github.PullRequest {
+	if sha == "" {
+		return nil
+	}
+	if pr, ok := commitPullRequestCache[sha]; ok {
+		return pr
+	}
+	if getClient() == nil {
+		log.Println("getPullRequestForCommit: GitHub client not initialized.")
+		return nil
+	}
+
+	var pullRequests []*github.PullRequest
+	for {
+		var err error
+		pullRequests, _, err = getClient().PullRequests.ListPullRequestsWithCommit(fetcherContext(fetcherRuns), owner, repoName, sha, nil)
+		recordAPIError(err)
+		if rlErr, ok := err.(*github.RateLimitError); ok {
+			log.Printf("ListPullRequestsWithCommit ratelimited for %s (%s/%s). Pausing until %s", sha, owner, repoName, rlErr.Rate.Reset.Time.String())
+			sleepUntilRateLimitReset(rlErr.Rate.Reset.Time)
+			continue
+		} else if err != nil {
+			log.Printf("ListPullRequestsWithCommit error for %s (%s/%s): %v", sha, owner, repoName, err)
+			return nil // Not cached, retried next cycle
+		}
+		break
+	}
+
+	var resolved *github.PullRequest
+	for _, pr := range pullRequests {
+		if pr == nil {
+			continue
+		}
+		if pr.MergedAt != nil {
+			resolved = pr
+			break
+		}
+		if resolved == nil {
+			resolved = pr
+		}
+	}
+	commitPullRequestCache[sha] = resolved
+	return resolved
+}
+
+// pruneCommitPullRequestCache drops cached pull requests for commits that were not seen during the last cycle.
+func pruneCommitPullRequestCache(seenSHAs map[string]bool) {
+	for sha := range commitPullRequestCache {
+		if !seenSHAs[sha] {
+			delete(commitPullRequestCache, sha)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+)
+
+var (
+	repoActionsUsageGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_repo_actions_usage_seconds",
+			Help: "Billable seconds used by all the workflows of a private repository for a given OS during the current billing cycle. " +
+				"Only GitHub-hosted runners are billed.",
+		},
+		[]string{"repo", "os"},
+	)
+
+	// Key: repository, then OS. Usage in milliseconds exported by the last cycle, kept for repositories
+	// whose usage couldn't be fully fetched by the next one.
+	lastRepoUsageMs = make(map[string]map[string]int64)
+)
+
+// getRepoBillingFromGithub is the main goroutine for fetching the billable usage of each repository.
+func getRepoBillingFromGithub() {
+	if getClient() == nil {
+		log.Println("getRepoBillingFromGithub: GitHub client not initialized.")
+		return
+	}
+
+	// Billable usage only grows over the billing cycle, so it is refreshed on the slower cadence of the workflow billing.
+	refreshInterval := time.Duration(config.Github.Refresh) * 5 * time.Second
+	if config.Github.Refresh <= 0 {
+		refreshInterval = 300 * time.Second
+	}
+	log.Printf("getRepoBillingFromGithub will refresh every %v", refreshInterval)
+	expectFetcherCycles("getRepoBillingFromGithub")
+	sleepStartupJitter("getRepoBillingFromGithub")
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		sleepTickJitter()
+		if isCollectionPaused() {
+			continue
+		}
+		cycleStart := time.Now()
+		collectRepoBilling()
+		observeCycleDuration("getRepoBillingFromGithub", time.Since(cycleStart), refreshInterval)
+	}
+}
+
+// collectRepoBilling runs a single billing collection cycle, summing the usage of the cached workflow definitions
+// of each private repository. Public repositories aren't billed and cost no call; repositories whose visibility
+// is unknown are fetched. When no repository usage could be fetched, the gauge keeps its last known values.
+func collectRepoBilling() {
+	cachedWorkflows := getWorkflowsSnapshot()
+	if len(cachedWorkflows) == 0 {
+		return
+	}
+	log.Println("getRepoBillingFromGithub: Starting repository billing collection cycle...")
+
+	usageMs := make(map[string]map[string]int64) // Key: repository, then OS
+	attempted, anyFetched := false, false
+	for repoFullName, repoWorkflowsMap := range cachedWorkflows {
+		if repo, ok := getRepoMetadata(repoFullName); ok && !repo.GetPrivate() {
+			continue
+		}
+		ownerAndRepo := strings.Split(repoFullName, "/")
+		if len(ownerAndRepo) != 2 {
+			log.Printf("getRepoBillingFromGithub: Invalid repository format '%s'. Skipping.", repoFullName)
+			continue
+		}
+		owner, repoName := ownerAndRepo[0], ownerAndRepo[1]
+
+		attempted = true
+		complete := true
+		repoUsageMs := make(map[string]int64)
+		for workflowID := range repoWorkflowsMap {
+			usageData := getCachedWorkflowUsage(owner, repoName, workflowID)
+			if usageData == nil {
+				complete = false
+				break
+			}
+			if billMap := usageData.GetBillable(); billMap != nil {
+				for osType, billData := range *billMap {
+					repoUsageMs[strings.ToUpper(osType)] += billData.GetTotalMS()
+				}
+			}
+		}
+		if complete {
+			usageMs[repoFullName] = repoUsageMs
+			anyFetched = true
+		} else if previous, ok := lastRepoUsageMs[repoFullName]; ok { // A partial sum would show a drop in the usage
+			usageMs[repoFullName] = previous
+		}
+	}
+	degraded := attempted && !anyFetched
+	setFetcherDegraded("getRepoBillingFromGithub", degraded)
+	if degraded {
+		return // Keep the last known usage
+	}
+
+	repoActionsUsageGauge.Reset()
+	for repoFullName, repoUsageMs := range usageMs {
+		for osType, totalMs := range repoUsageMs {
+			repoActionsUsageGauge.WithLabelValues(repoFullName, osType).Set(float64(totalMs) / 1000)
+		}
+	}
+	lastRepoUsageMs = usageMs
+	log.Println("getRepoBillingFromGithub: Finished repository billing collection cycle.")
+}
@@ -0,0 +1,184 @@
+package metrics
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+
+	"github.com/google/go-github/v72/github"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// The REST API doesn't expose the runner scale sets of Actions Runner Controller (ARC) themselves, only the
+// runner groups they register their runners in. A scale set is reported as the runner group holding its runners,
+// so scale sets need a runner group each to be told apart. Their desired replica count isn't available.
+var (
+	runnerScaleSetReplicasGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_runner_scale_set_replicas",
+			Help: "Number of runners registered in an organization runner group, the current replicas of the ARC scale set using it.",
+		},
+		[]string{"org", "scale_set"},
+	)
+
+	runnerScaleSetBusyReplicasGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_runner_scale_set_busy_replicas",
+			Help: "Number of runners of an organization runner group currently running a job.",
+		},
+		[]string{"org", "scale_set"},
+	)
+
+	// Organizations whose runner groups endpoint returned 403 or 404 (plan without runner groups,
+	// missing permission), so they aren't called again.
+	runnerGroupsUnavailable = make(map[string]bool)
+)
+
+// getOrgRunnerGroups lists the runner groups of an organization. It returns false on error.
+func getOrgRunnerGroups(orgaName string) ([]*github.RunnerGroup, bool) {
+	var groups []*github.RunnerGroup
+	opt := &github.ListOrgRunnerGroupOptions{ListOptions: github.ListOptions{PerPage: getPerPage()}}
+	for {
+		resp, httpResp, err := getClient().Actions.ListOrganizationRunnerGroups(fetcherContext(fetcherRunners), orgaName, opt)
+		recordAPIError(err)
+		if rlErr, ok := err.(*github.RateLimitError); ok {
+			log.Printf("ListOrganizationRunnerGroups ratelimited for org %s. Pausing until %s", orgaName, rlErr.Rate.Reset.Time.String())
+			sleepUntilRateLimitReset(rlErr.Rate.Reset.Time)
+			continue
+		} else if err != nil {
+			if httpResp != nil && (httpResp.StatusCode == http.StatusForbidden || httpResp.StatusCode == http.StatusNotFound) {
+				log.Printf("Runner groups of org %s are not available, the organization plan may not support them or the token lacks "+
+					"the admin:org scope (%v). Disabling scale set metrics for it.", orgaName, err)
+				runnerGroupsUnavailable[orgaName] = true
+				return nil, false
+			}
+			log.Printf("ListOrganizationRunnerGroups error for org %s: %v", orgaName, err)
+			return nil, false
+		}
+		if resp != nil {
+			groups = append(groups, resp.RunnerGroups...)
+		}
+
+		recordPageFetched(httpResp)
+		if getNextPage(httpResp) == 0 {
+			break
+		}
+		opt.Page = getNextPage(httpResp)
+	}
+	return groups, true
+}
+
+// getRunnerGroupRunners lists the runners of an organization runner group. It returns false on error.
+func getRunnerGroupRunners(orgaName string, groupID int64) ([]*github.Runner, bool) {
+	var runners []*github.Runner
+	opt := &github.ListOptions{PerPage: getPerPage()}
+	for {
+		resp, httpResp, err := getClient().Actions.ListRunnerGroupRunners(fetcherContext(fetcherRunners), orgaName, groupID, opt)
+		recordAPIError(err)
+		if rlErr, ok := err.(*github.RateLimitError); ok {
+			log.Printf("ListRunnerGroupRunners ratelimited for group %d of org %s. Pausing until %s", groupID, orgaName, rlErr.Rate.Reset.Time.String())
+			sleepUntilRateLimitReset(rlErr.Rate.Reset.Time)
+			continue
+		} else if err != nil {
+			log.Printf("ListRunnerGroupRunners error for group %d of org %s: %v", groupID, orgaName, err)
+			return nil, false
+		}
+		if resp != nil {
+			runners = append(runners, resp.Runners...)
+		}
+
+		recordPageFetched(httpResp)
+		if getNextPage(httpResp) == 0 {
+			break
+		}
+		opt.Page = getNextPage(httpResp)
+	}
+	return runners, true
+}
+
+// getRunnerScaleSetsFromGithub is the main goroutine for fetching the replicas of the runner scale sets.
+func getRunnerScaleSetsFromGithub() {
+	if getClient() == nil {
+		log.Println("getRunnerScaleSetsFromGithub: GitHub client not initialized.")
+		return
+	}
+	if len(config.Github.Organizations.Value()) == 0 {
+		log.Println("getRunnerScaleSetsFromGithub: No organizations configured. Skipping runner scale set collection.")
+		return
+	}
+
+	refreshInterval := time.Duration(config.Github.Refresh) * time.Second
+	if config.Github.Refresh <= 0 {
+		refreshInterval = 60 * time.Second
+	}
+	log.Printf("getRunnerScaleSetsFromGithub will refresh every %v", refreshInterval)
+	expectFetcherCycles("getRunnerScaleSetsFromGithub")
+	sleepStartupJitter("getRunnerScaleSetsFromGithub")
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		sleepTickJitter()
+		if isCollectionPaused() {
+			continue
+		}
+		cycleStart := time.Now()
+		collectRunnerScaleSets()
+		observeCycleDuration("getRunnerScaleSetsFromGithub", time.Since(cycleStart), refreshInterval)
+	}
+}
+
+// collectRunnerScaleSets runs a single runner scale set collection cycle over the organizations.
+// A group whose runners can't be listed keeps no series rather than showing a drop to 0 replicas. When the runner
+// groups of every organization can't be listed, the gauges keep their last known values.
+func collectRunnerScaleSets() {
+	type scaleSetKey struct {
+		org      string
+		scaleSet string
+	}
+	replicas := make(map[scaleSetKey]int)
+	busyReplicas := make(map[scaleSetKey]int)
+	attempted, anyFetched := false, false
+	for _, orgaName := range config.Github.Organizations.Value() {
+		if orgaName == "" || runnerGroupsUnavailable[orgaName] {
+			continue
+		}
+		groups, ok := getOrgRunnerGroups(orgaName)
+		if !ok {
+			// Organizations found without runner groups are disabled rather than failing
+			attempted = attempted || !runnerGroupsUnavailable[orgaName]
+			continue
+		}
+		attempted, anyFetched = true, true
+		for _, group := range groups {
+			if group == nil {
+				continue
+			}
+			runners, ok := getRunnerGroupRunners(orgaName, group.GetID())
+			if !ok {
+				continue
+			}
+			key := scaleSetKey{orgaName, group.GetName()}
+			replicas[key] = len(runners)
+			for _, runner := range runners {
+				if runner != nil && runner.GetBusy() {
+					busyReplicas[key]++
+				}
+			}
+		}
+	}
+	degraded := attempted && !anyFetched
+	setFetcherDegraded("getRunnerScaleSetsFromGithub", degraded)
+	if degraded {
+		return // Keep the last known replicas
+	}
+
+	runnerScaleSetReplicasGauge.Reset()
+	runnerScaleSetBusyReplicasGauge.Reset()
+	for key, count := range replicas {
+		runnerScaleSetReplicasGauge.WithLabelValues(key.org, key.scaleSet).Set(float64(count))
+		runnerScaleSetBusyReplicasGauge.WithLabelValues(key.org, key.scaleSet).Set(float64(busyReplicas[key]))
+	}
+}
@@ -1,12 +1,11 @@
 package metrics
 
 import (
-	"context"
 	"log"
 	"strconv"
 	"time"
 
-	"github.com/spendesk/github-actions-exporter/pkg/config"
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
 
 	"github.com/google/go-github/v72/github"
 	"github.com/prometheus/client_golang/prometheus"
@@ -22,46 +21,67 @@ var (
 	)
 )
 
-func getAllEnterpriseRunners() []*github.Runner {
+// getAllEnterpriseRunners lists the self-hosted runners of the enterprise. It returns false on error.
+func getAllEnterpriseRunners() ([]*github.Runner, bool) {
 	var runners []*github.Runner
-	opt := &github.ListOptions{PerPage: 200}
+	opt := &github.ListRunnersOptions{ListOptions: github.ListOptions{PerPage: getPerPage()}} // Enterprise.ListRunners takes *ListRunnersOptions in v72
 
 	for {
-		resp, rr, err := client.Enterprise.ListRunners(context.Background(), config.EnterpriseName, nil)
+		resp, rr, err := getClient().Enterprise.ListRunners(fetcherContext(fetcherRunners), config.EnterpriseName, opt)
+		recordAPIError(err)
 		if rl_err, ok := err.(*github.RateLimitError); ok {
 			log.Printf("ListRunners ratelimited. Pausing until %s", rl_err.Rate.Reset.Time.String())
-			time.Sleep(time.Until(rl_err.Rate.Reset.Time))
+			sleepUntilRateLimitReset(rl_err.Rate.Reset.Time)
 			continue
 		} else if err != nil {
 			log.Printf("ListRunners error for enterprise %s: %s", config.EnterpriseName, err.Error())
-			return nil
+			return nil, false
 		}
 
-		runners = append(runners, resp.Runners...)
-		if rr.NextPage == 0 {
+		if resp != nil {
+			runners = append(runners, resp.Runners...)
+		}
+		recordPageFetched(rr)
+		if getNextPage(rr) == 0 {
 			break
 		}
-		opt.Page = rr.NextPage
+		opt.Page = getNextPage(rr)
 	}
 
-	return runners
+	return runners, true
 }
 
 func getRunnersEnterpriseFromGithub() {
 	if config.EnterpriseName == "" {
 		return
 	}
+	expectFetcherCycles("getRunnersEnterpriseFromGithub")
+	sleepStartupJitter("getRunnersEnterpriseFromGithub")
+	refreshInterval := time.Duration(config.Github.Refresh) * time.Second
 	for {
-		runners := getAllEnterpriseRunners()
-
-		for _, runner := range runners {
-			var integerStatus float64
-			if integerStatus = 0; runner.GetStatus() == "online" {
-				integerStatus = 1
-			}
-			runnersEnterpriseGauge.WithLabelValues(*runner.OS, *runner.Name, strconv.FormatInt(runner.GetID(), 10)).Set(integerStatus)
+		if !isCollectionPaused() {
+			cycleStart := time.Now()
+			collectEnterpriseRunners()
+			observeCycleDuration("getRunnersEnterpriseFromGithub", time.Since(cycleStart), refreshInterval)
 		}
+		time.Sleep(refreshInterval)
+		sleepTickJitter()
+	}
+}
+
+// collectEnterpriseRunners runs a single enterprise runner collection cycle.
+func collectEnterpriseRunners() {
+	if config.EnterpriseName == "" {
+		return
+	}
+	runners, ok := getAllEnterpriseRunners()
+	setFetcherDegraded("getRunnersEnterpriseFromGithub", !ok)
+	if !ok {
+		return // Keep the last known runners
+	}
 
-		time.Sleep(time.Duration(config.Github.Refresh) * time.Second)
+	runnersEnterpriseGauge.Reset()
+	for _, runner := range runners {
+		runnersEnterpriseGauge.WithLabelValues(*runner.OS, *runner.Name, strconv.FormatInt(runner.GetID(), 10)).Set(getRunnerStatusValue(runner))
 	}
 }
@@ -1,13 +1,12 @@
 package metrics
 
 import (
-	"context"
 	"log"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/spendesk/github-actions-exporter/pkg/config"
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
 
 	"github.com/google/go-github/v72/github" // <<< Ensure v72
 	"github.com/prometheus/client_golang/prometheus"
@@ -23,44 +22,52 @@ var (
 	)
 )
 
-func getAllRepoRunners(owner string, repoName string) []*github.Runner {
-	if client == nil {
+// runnersFetch is the result of listing the runners of a repository or organization. ok is false when
+// the listing failed, in which case runners may be partial.
+type runnersFetch struct {
+	runners []*github.Runner
+	ok      bool
+}
+
+func getAllRepoRunners(owner string, repoName string) runnersFetch {
+	if getClient() == nil {
 		log.Println("getAllRepoRunners: GitHub client not initialized.")
-		return nil
+		return runnersFetch{}
 	}
 
 	var allRunners []*github.Runner
-	// CORRECTED: ListRunners and ListOrganizationRunners take *ListOptions in v72
-	opt := &github.ListOptions{PerPage: 100} // Maximize items per page
+	opt := &github.ListRunnersOptions{ListOptions: github.ListOptions{PerPage: getPerPage()}}
 
 	log.Printf("Fetching repository runners for %s/%s", owner, repoName)
 	for {
-		runnersResponse, httpResp, err := client.Actions.ListRunners(context.Background(), owner, repoName, opt)
+		runnersResponse, httpResp, err := getClient().Actions.ListRunners(fetcherContext(fetcherRunners), owner, repoName, opt)
+		recordAPIError(err)
 		if rlErr, ok := err.(*github.RateLimitError); ok {
 			log.Printf("ListRunners ratelimited for %s/%s. Pausing until %s", owner, repoName, rlErr.Rate.Reset.Time.String())
-			time.Sleep(time.Until(rlErr.Rate.Reset.Time))
+			sleepUntilRateLimitReset(rlErr.Rate.Reset.Time)
 			continue
 		} else if err != nil {
 			log.Printf("ListRunners error for repo %s/%s: %v", owner, repoName, err)
-			return allRunners
+			return runnersFetch{allRunners, false}
 		}
 
 		if runnersResponse != nil && runnersResponse.Runners != nil {
 			allRunners = append(allRunners, runnersResponse.Runners...)
 		}
 
-		if httpResp.NextPage == 0 {
+		recordPageFetched(httpResp)
+		if getNextPage(httpResp) == 0 {
 			break
 		}
-		opt.Page = httpResp.NextPage // ListOptions has a Page field
+		opt.ListOptions.Page = getNextPage(httpResp)
 	}
 	log.Printf("Fetched %d runners for repository %s/%s", len(allRunners), owner, repoName)
-	return allRunners
+	return runnersFetch{allRunners, true}
 }
 
 // getRunnersFromGithub is the main goroutine for fetching repository-level runner metrics.
 func getRunnersFromGithub() {
-	if client == nil {
+	if getClient() == nil {
 		log.Println("getRunnersFromGithub: GitHub client not initialized.")
 		return
 	}
@@ -74,49 +81,75 @@ func getRunnersFromGithub() {
 		refreshInterval = 60 * time.Second // Default if not set
 	}
 	log.Printf("getRunnersFromGithub will refresh every %v", refreshInterval)
+	expectFetcherCycles("getRunnersFromGithub")
+	sleepStartupJitter("getRunnersFromGithub")
 	ticker := time.NewTicker(refreshInterval)
 	defer ticker.Stop()
 
 	for range ticker.C {
-		if len(repositories) == 0 {
+		sleepTickJitter()
+		if isCollectionPaused() {
 			continue
 		}
-		log.Printf("getRunnersFromGithub: Starting repository runner collection cycle for %d repositories.", len(repositories))
-		runnersGauge.Reset()
+		cycleStart := time.Now()
+		collectRepoRunners()
+		observeCycleDuration("getRunnersFromGithub", time.Since(cycleStart), refreshInterval)
+	}
+}
 
-		for _, repoFullName := range repositories {
-			ownerAndRepo := strings.Split(repoFullName, "/")
-			if len(ownerAndRepo) != 2 {
-				log.Printf("getRunnersFromGithub: Invalid repository format '%s'. Skipping.", repoFullName)
-				continue
-			}
-			owner, repoName := ownerAndRepo[0], ownerAndRepo[1]
+// collectRepoRunners runs a single repository runner collection cycle.
+func collectRepoRunners() {
+	if len(repositories) == 0 {
+		return
+	}
+	log.Printf("getRunnersFromGithub: Starting repository runner collection cycle for %d repositories.", len(repositories))
+	runnersByRepo := make(map[string]runnersFetch)
+	anyFetched := false
+	for _, repoFullName := range repositories {
+		ownerAndRepo := strings.Split(repoFullName, "/")
+		if len(ownerAndRepo) != 2 {
+			log.Printf("getRunnersFromGithub: Invalid repository format '%s'. Skipping.", repoFullName)
+			continue
+		}
+		runnersByRepo[repoFullName] = getAllRepoRunners(ownerAndRepo[0], ownerAndRepo[1])
+		anyFetched = anyFetched || runnersByRepo[repoFullName].ok
+	}
+	degraded := len(runnersByRepo) > 0 && !anyFetched
+	setFetcherDegraded("getRunnersFromGithub", degraded)
+	if degraded {
+		return // Keep the last known runners
+	}
 
-			fetchedRunners := getAllRepoRunners(owner, repoName)
-			if fetchedRunners == nil {
+	runnersGauge.Reset()
+	seenRunnerIDs := make(map[int64]bool)
+	utilization := make(runnerUtilization)
+	for _, repoFullName := range repositories {
+		fetchedRunners := runnersByRepo[repoFullName].runners
+		if fetchedRunners == nil {
+			continue
+		}
+
+		for _, runner := range fetchedRunners {
+			if runner == nil || runner.ID == nil || runner.Name == nil || runner.OS == nil || runner.Status == nil || runner.Busy == nil {
+				log.Printf("getRunnersFromGithub: Incomplete runner data for an entry in %s. Skipping.", repoFullName)
 				continue
 			}
 
-			for _, runner := range fetchedRunners {
-				if runner == nil || runner.ID == nil || runner.Name == nil || runner.OS == nil || runner.Status == nil || runner.Busy == nil {
-					log.Printf("getRunnersFromGithub: Incomplete runner data for an entry in %s. Skipping.", repoFullName)
-					continue
-				}
-
-				var statusValue float64 = 0
-				if runner.GetStatus() == "online" {
-					statusValue = 1
-				}
-
-				runnersGauge.WithLabelValues(
-					repoFullName,
-					runner.GetOS(),
-					runner.GetName(),
-					strconv.FormatInt(runner.GetID(), 10),
-					strconv.FormatBool(runner.GetBusy()),
-				).Set(statusValue)
-			}
+			statusValue := getRunnerStatusValue(runner)
+			seenRunnerIDs[runner.GetID()] = true
+			runnerTransitions.observe("repo", runner.GetID(), runner.GetStatus() == "online")
+			utilization.add(repoFullName, runner)
+
+			runnersGauge.WithLabelValues(
+				repoFullName,
+				runner.GetOS(),
+				runner.GetName(),
+				strconv.FormatInt(runner.GetID(), 10),
+				strconv.FormatBool(runner.GetBusy()),
+			).Set(statusValue)
 		}
-		log.Println("getRunnersFromGithub: Finished repository runner collection cycle.")
 	}
+	runnerTransitions.forgetMissing("repo", seenRunnerIDs)
+	utilization.export("repo")
+	log.Println("getRunnersFromGithub: Finished repository runner collection cycle.")
 }
\ No newline at end of file
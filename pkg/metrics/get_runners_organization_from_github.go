@@ -1,12 +1,11 @@
 package metrics
 
 import (
-	"context"
 	"log"
 	"strconv"
 	"time"
 
-	"github.com/spendesk/github-actions-exporter/pkg/config"
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
 
 	"github.com/google/go-github/v72/github" // <<< Ensure v72
 	"github.com/prometheus/client_golang/prometheus"
@@ -22,44 +21,45 @@ var (
 	)
 )
 
-func getAllOrgRunners(orgaName string) []*github.Runner {
-	if client == nil {
+func getAllOrgRunners(orgaName string) runnersFetch {
+	if getClient() == nil {
 		log.Println("getAllOrgRunners: GitHub client not initialized.")
-		return nil
+		return runnersFetch{}
 	}
 
 	var allRunners []*github.Runner
-	// CORRECTED: ListRunners and ListOrganizationRunners take *ListOptions in v72
-	opt := &github.ListOptions{PerPage: 100} // Maximize items per page
+	opt := &github.ListRunnersOptions{ListOptions: github.ListOptions{PerPage: getPerPage()}}
 
 	log.Printf("Fetching organization runners for %s", orgaName)
 	for {
-		runnersResponse, httpResp, err := client.Actions.ListOrganizationRunners(context.Background(), orgaName, opt)
+		runnersResponse, httpResp, err := getClient().Actions.ListOrganizationRunners(fetcherContext(fetcherRunners), orgaName, opt)
+		recordAPIError(err)
 		if rlErr, ok := err.(*github.RateLimitError); ok {
 			log.Printf("ListOrganizationRunners ratelimited for org %s. Pausing until %s", orgaName, rlErr.Rate.Reset.Time.String())
-			time.Sleep(time.Until(rlErr.Rate.Reset.Time))
+			sleepUntilRateLimitReset(rlErr.Rate.Reset.Time)
 			continue
 		} else if err != nil {
 			log.Printf("ListOrganizationRunners error for org %s: %v", orgaName, err)
-			return allRunners
+			return runnersFetch{allRunners, false}
 		}
 
 		if runnersResponse != nil && runnersResponse.Runners != nil {
 			allRunners = append(allRunners, runnersResponse.Runners...)
 		}
 
-		if httpResp.NextPage == 0 {
+		recordPageFetched(httpResp)
+		if getNextPage(httpResp) == 0 {
 			break
 		}
-		opt.Page = httpResp.NextPage // ListOptions has a Page field
+		opt.ListOptions.Page = getNextPage(httpResp)
 	}
 	log.Printf("Fetched %d runners for organization %s", len(allRunners), orgaName)
-	return allRunners
+	return runnersFetch{allRunners, true}
 }
 
 // getRunnersOrganizationFromGithub is the main goroutine for fetching organization-level runner metrics.
 func getRunnersOrganizationFromGithub() {
-	if client == nil {
+	if getClient() == nil {
 		log.Println("getRunnersOrganizationFromGithub: GitHub client not initialized.")
 		return
 	}
@@ -78,46 +78,78 @@ func getRunnersOrganizationFromGithub() {
 		refreshInterval = 60 * time.Second
 	}
 	log.Printf("getRunnersOrganizationFromGithub will refresh every %v", refreshInterval)
+	expectFetcherCycles("getRunnersOrganizationFromGithub")
+	sleepStartupJitter("getRunnersOrganizationFromGithub")
 	ticker := time.NewTicker(refreshInterval)
 	defer ticker.Stop()
 
 	for range ticker.C {
-		if config.Github.Organizations.Value() == nil || len(config.Github.Organizations.Value()) == 0 {
+		sleepTickJitter()
+		if isCollectionPaused() {
 			continue
 		}
-		log.Printf("getRunnersOrganizationFromGithub: Starting organization runner collection cycle for %d organization(s).", len(config.Github.Organizations.Value()))
-		runnersOrganizationGauge.Reset()
+		cycleStart := time.Now()
+		collectOrganizationRunners()
+		observeCycleDuration("getRunnersOrganizationFromGithub", time.Since(cycleStart), refreshInterval)
+	}
+}
 
-		for _, orgaName := range config.Github.Organizations.Value() {
-			if orgaName == "" {
-				continue
-			}
+// collectOrganizationRunners runs a single organization runner collection cycle.
+func collectOrganizationRunners() {
+	if config.Github.Organizations.Value() == nil || len(config.Github.Organizations.Value()) == 0 {
+		return
+	}
+	log.Printf("getRunnersOrganizationFromGithub: Starting organization runner collection cycle for %d organization(s).", len(config.Github.Organizations.Value()))
+
+	var orgaNames []string
+	for _, orgaName := range config.Github.Organizations.Value() {
+		if orgaName != "" {
+			orgaNames = append(orgaNames, orgaName)
+		}
+	}
+	// Fetch organizations concurrently so a throttled one doesn't stall the others,
+	// then write all results to the gauge from this goroutine.
+	runnersByOrga := fetchConcurrently(orgaNames, getAllOrgRunners)
+	anyFetched := false
+	for _, fetched := range runnersByOrga {
+		anyFetched = anyFetched || fetched.ok
+	}
+	degraded := len(orgaNames) > 0 && !anyFetched
+	setFetcherDegraded("getRunnersOrganizationFromGithub", degraded)
+	if degraded {
+		return // Keep the last known runners
+	}
+	runnersOrganizationGauge.Reset()
+	seenRunnerIDs := make(map[int64]bool)
+	utilization := make(runnerUtilization)
 
-			fetchedRunners := getAllOrgRunners(orgaName)
-			if fetchedRunners == nil {
+	for _, orgaName := range orgaNames {
+		fetchedRunners := runnersByOrga[orgaName].runners
+		if fetchedRunners == nil {
+			continue
+		}
+
+		for _, runner := range fetchedRunners {
+			if runner == nil || runner.ID == nil || runner.Name == nil || runner.OS == nil || runner.Status == nil || runner.Busy == nil {
+				log.Printf("getRunnersOrganizationFromGithub: Incomplete runner data for an entry in org %s. Skipping.", orgaName)
 				continue
 			}
 
-			for _, runner := range fetchedRunners {
-				if runner == nil || runner.ID == nil || runner.Name == nil || runner.OS == nil || runner.Status == nil || runner.Busy == nil {
-					log.Printf("getRunnersOrganizationFromGithub: Incomplete runner data for an entry in org %s. Skipping.", orgaName)
-					continue
-				}
-
-				var statusValue float64 = 0
-				if runner.GetStatus() == "online" {
-					statusValue = 1
-				}
-
-				runnersOrganizationGauge.WithLabelValues(
-					orgaName,
-					runner.GetOS(),
-					runner.GetName(),
-					strconv.FormatInt(runner.GetID(), 10),
-					strconv.FormatBool(runner.GetBusy()),
-				).Set(statusValue)
-			}
+			statusValue := getRunnerStatusValue(runner)
+			seenRunnerIDs[runner.GetID()] = true
+			runnerTransitions.observe("organization", runner.GetID(), runner.GetStatus() == "online")
+			utilization.add(orgaName, runner)
+
+			runnersOrganizationGauge.WithLabelValues(
+				orgaName,
+				runner.GetOS(),
+				runner.GetName(),
+				strconv.FormatInt(runner.GetID(), 10),
+				strconv.FormatBool(runner.GetBusy()),
+			).Set(statusValue)
 		}
-		log.Println("getRunnersOrganizationFromGithub: Finished organization runner collection cycle.")
 	}
+	runnerTransitions.forgetMissing("organization", seenRunnerIDs)
+	utilization.export("organization")
+	log.Println("getRunnersOrganizationFromGithub: Finished organization runner collection cycle.")
 }
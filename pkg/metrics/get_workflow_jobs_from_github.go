@@ -0,0 +1,282 @@
+package metrics
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+
+	"github.com/google/go-github/v72/github"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	workflowJobRunnerTypeGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_workflow_job_runner_type",
+			Help: "Number of jobs, from runs in the fetch window, that executed on a GitHub-hosted or self-hosted runner.",
+		},
+		[]string{"repo", "workflow_name", "runner_type"},
+	)
+
+	jobsQueuedGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_jobs_queued_total",
+			Help: "Number of jobs across all monitored repositories currently queued and waiting for a runner, by requested runner labels.",
+		},
+		[]string{"labels"},
+	)
+
+	workflowRunsByOSGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_workflow_runs_by_os",
+			Help: "Number of runs, in the fetch window, with jobs on a runner OS (linux, windows, macos or unknown), derived from the job runner labels.",
+		},
+		[]string{"repo", "workflow_name", "os"},
+	)
+
+	workflowRunFailureClassGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_workflow_run_failure_class",
+			Help: "Number of failed runs of a workflow in the fetch window by failure class: infrastructure_failure when no job " +
+				"of the run was picked up by a runner (invalid workflow file, missing secrets, ...), job_failure otherwise.",
+		},
+		[]string{"repo", "workflow_name", "class"},
+	)
+
+	hostedRunnerQueueDepthGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_hosted_runner_queue_depth",
+			Help: "Number of runs of an organization with at least one job queued for a GitHub-hosted runner, " +
+				"from the runs in the fetch window. Jobs requesting SELF_HOSTED_RUNNER_LABELS are not counted.",
+		},
+		[]string{"org"},
+	)
+
+	workflowRunOverheadGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_workflow_run_overhead_seconds",
+			Help: "Average time, over the completed runs of a workflow in the fetch window, during which no job of the run was executing: " +
+				"run duration minus the time covered by its jobs. Large values point to runner starvation or scheduling delays.",
+		},
+		[]string{"repo", "workflow_name"},
+	)
+
+	// Jobs of completed run attempts never change, so they are kept between cycles.
+	// Entries for runs that fall out of the fetch window are dropped by pruneWorkflowJobsCache.
+	workflowJobsCache = make(map[workflowJobsCacheKey][]*github.WorkflowJob)
+)
+
+type jobRunnerTypeKey struct {
+	repo         string
+	workflowName string
+	runnerType   string
+}
+
+// runOverheadSum accumulates the overhead of the runs of a workflow to export their average.
+type runOverheadSum struct {
+	seconds float64
+	runs    int
+}
+
+type runOSKey struct {
+	repo         string
+	workflowName string
+	os           string
+}
+
+type workflowJobsCacheKey struct {
+	runID   int64
+	attempt int
+}
+
+const (
+	runnerTypeGithubHosted = "github-hosted"
+	runnerTypeSelfHosted   = "self-hosted"
+)
+
+// Values of the class label of github_workflow_run_failure_class.
+const (
+	failureClassInfrastructure = "infrastructure_failure"
+	failureClassJob            = "job_failure"
+)
+
+type runFailureClassKey struct {
+	repo         string
+	workflowName string
+	class        string
+}
+
+const (
+	runnerOSLinux   = "linux"
+	runnerOSWindows = "windows"
+	runnerOSMacOS   = "macos"
+	runnerOSUnknown = "unknown"
+)
+
+// getAllJobsForRun fetches the jobs of the latest attempt of a workflow run. It returns false on error,
+// along with the jobs of the pages fetched before it.
+func getAllJobsForRun(owner string, repoName string, runID int64) ([]*github.WorkflowJob, bool) {
+	if getClient() == nil {
+		log.Println("getAllJobsForRun: GitHub client not initialized.")
+		return nil, false
+	}
+
+	var allJobs []*github.WorkflowJob
+	opt := &github.ListWorkflowJobsOptions{
+		Filter:      "latest",
+		ListOptions: github.ListOptions{PerPage: getPerPage()},
+	}
+
+	for {
+		jobsResponse, httpResp, err := getClient().Actions.ListWorkflowJobs(fetcherContext(fetcherJobs), owner, repoName, runID, opt)
+		recordAPIError(err)
+		if rlErr, ok := err.(*github.RateLimitError); ok {
+			log.Printf("ListWorkflowJobs ratelimited for run %d (%s/%s). Pausing until %s", runID, owner, repoName, rlErr.Rate.Reset.Time.String())
+			sleepUntilRateLimitReset(rlErr.Rate.Reset.Time)
+			continue
+		} else if err != nil {
+			log.Printf("ListWorkflowJobs error for run %d (%s/%s): %v", runID, owner, repoName, err)
+			return allJobs, false
+		}
+
+		if jobsResponse != nil && jobsResponse.Jobs != nil {
+			allJobs = append(allJobs, jobsResponse.Jobs...)
+		}
+
+		recordPageFetched(httpResp)
+		if getNextPage(httpResp) == 0 {
+			break
+		}
+		opt.Page = getNextPage(httpResp)
+	}
+	return allJobs, true
+}
+
+// getJobsForRun returns the jobs of a run, served from webhook deliveries once seeded by a complete listing,
+// or from workflowJobsCache when the run attempt is completed. It returns false when they
+// couldn't be fetched, the jobs returned then being partial.
+func getJobsForRun(owner string, repoName string, run *github.WorkflowRun) ([]*github.WorkflowJob, bool) {
+	if jobs, ok := webhookRuns.getJobs(run.GetID(), run.GetRunAttempt()); ok {
+		return jobs, true
+	}
+	key := workflowJobsCacheKey{runID: run.GetID(), attempt: run.GetRunAttempt()}
+	if jobs, ok := workflowJobsCache[key]; ok {
+		return jobs, true
+	}
+
+	jobs, ok := getAllJobsForRun(owner, repoName, run.GetID())
+	if ok {
+		webhookRuns.seedJobs(owner+"/"+repoName, run, jobs)
+	}
+	if run.GetStatus() == "completed" && ok {
+		workflowJobsCache[key] = jobs
+	}
+	return jobs, ok
+}
+
+// pruneWorkflowJobsCache drops cached jobs for runs that were not seen during the last cycle.
+func pruneWorkflowJobsCache(seenRunIDs map[int64]bool) {
+	for key := range workflowJobsCache {
+		if !seenRunIDs[key.runID] {
+			delete(workflowJobsCache, key)
+		}
+	}
+}
+
+// getJobsBusySeconds returns the time during which at least one of the jobs of a completed run was executing,
+// overlapping (parallel) jobs being counted once. It returns false when a job hasn't completed or no job ran.
+func getJobsBusySeconds(jobs []*github.WorkflowJob) (float64, bool) {
+	type interval struct{ start, end time.Time }
+	var intervals []interval
+	for _, job := range jobs {
+		if job == nil {
+			continue
+		}
+		if job.GetStatus() != "completed" {
+			return 0, false
+		}
+		if job.StartedAt == nil || job.CompletedAt == nil || !job.GetCompletedAt().After(job.GetStartedAt().Time) {
+			continue // Skipped jobs never started
+		}
+		intervals = append(intervals, interval{job.GetStartedAt().Time, job.GetCompletedAt().Time})
+	}
+	if len(intervals) == 0 {
+		return 0, false
+	}
+
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].start.Before(intervals[j].start) })
+	var busy time.Duration
+	current := intervals[0]
+	for _, next := range intervals[1:] {
+		if next.start.After(current.end) {
+			busy += current.end.Sub(current.start)
+			current = next
+		} else if next.end.After(current.end) {
+			current.end = next.end
+		}
+	}
+	busy += current.end.Sub(current.start)
+	return busy.Seconds(), true
+}
+
+// getRunFailureClass classifies a failed run by whether any of its jobs started on a runner: a run failing before
+// (startup_failure, or failure without started jobs) broke on CI infrastructure rather than on the job logic.
+// It returns "" for runs that didn't fail, and when their jobs couldn't be fetched (jobsFetched false): a run
+// whose jobs are unknown can't be told apart from one without started jobs.
+func getRunFailureClass(run *github.WorkflowRun, jobs []*github.WorkflowJob, jobsFetched bool) string {
+	if !jobsFetched || (run.GetConclusion() != "failure" && run.GetConclusion() != "startup_failure") {
+		return ""
+	}
+	for _, job := range jobs {
+		if job != nil && job.GetRunnerName() != "" {
+			return failureClassJob
+		}
+	}
+	return failureClassInfrastructure
+}
+
+// isJobWaitingForRunner reports whether a job is queued for a runner. Jobs waiting on
+// an environment approval or on other jobs have a different status and are not counted.
+func isJobWaitingForRunner(job *github.WorkflowJob) bool {
+	return job.GetStatus() == "queued"
+}
+
+// getJobRunnerLabels returns the runner labels requested by a job as a sorted, comma-separated string.
+func getJobRunnerLabels(job *github.WorkflowJob) string {
+	labels := append([]string(nil), job.Labels...)
+	sort.Strings(labels)
+	return strings.Join(labels, ",")
+}
+
+// getJobRunnerType classifies a job as self-hosted when it requested any of the configured
+// self-hosted labels (SELF_HOSTED_RUNNER_LABELS, "self-hosted" by default), GitHub-hosted otherwise.
+func getJobRunnerType(job *github.WorkflowJob) string {
+	for _, jobLabel := range job.Labels {
+		for _, selfHostedLabel := range config.Metrics.SelfHostedRunnerLabels.Value() {
+			if strings.EqualFold(jobLabel, selfHostedLabel) {
+				return runnerTypeSelfHosted
+			}
+		}
+	}
+	return runnerTypeGithubHosted
+}
+
+// getJobRunnerOS derives the runner OS of a job from its labels: GitHub-hosted images
+// (ubuntu-latest, windows-2022, macos-14, ...) or the OS labels of self-hosted runners (Linux, Windows, macOS).
+func getJobRunnerOS(job *github.WorkflowJob) string {
+	for _, label := range job.Labels {
+		label = strings.ToLower(label)
+		switch {
+		case strings.HasPrefix(label, "ubuntu") || label == "linux":
+			return runnerOSLinux
+		case strings.HasPrefix(label, "windows"):
+			return runnerOSWindows
+		case strings.HasPrefix(label, "macos"):
+			return runnerOSMacOS
+		}
+	}
+	return runnerOSUnknown
+}
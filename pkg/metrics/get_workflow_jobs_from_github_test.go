@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v72/github"
+)
+
+func TestGetRunFailureClass(t *testing.T) {
+	startedJob := &github.WorkflowJob{RunnerName: github.Ptr("runner-1")}
+	unstartedJob := &github.WorkflowJob{}
+
+	tests := []struct {
+		name        string
+		conclusion  string
+		jobs        []*github.WorkflowJob
+		jobsFetched bool
+		want        string
+	}{
+		{"startup failure", "startup_failure", nil, true, failureClassInfrastructure},
+		{"failure without started jobs", "failure", []*github.WorkflowJob{unstartedJob}, true, failureClassInfrastructure},
+		{"job failure", "failure", []*github.WorkflowJob{unstartedJob, startedJob}, true, failureClassJob},
+		{"jobs API error", "failure", nil, false, ""},
+		{"jobs API error after a partial fetch", "startup_failure", []*github.WorkflowJob{unstartedJob}, false, ""},
+		{"success", "success", []*github.WorkflowJob{startedJob}, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := &github.WorkflowRun{Conclusion: github.Ptr(tt.conclusion)}
+			if got := getRunFailureClass(run, tt.jobs, tt.jobsFetched); got != tt.want {
+				t.Errorf("getRunFailureClass() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,13 +1,15 @@
 package metrics
 
 import (
-	"context"
+	"fmt"
 	"log"
+	"math"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/spendesk/github-actions-exporter/pkg/config" // Your config package
+	"github.com/markomanboi/github-actions-exporter/pkg/config" // Your config package
 
 	"github.com/google/go-github/v72/github" // <<< UPDATED to v72
 )
@@ -36,9 +38,61 @@ func getSafeInt(i *int) int {
 	return 0
 }
 
-// getFieldValue extracts basic, direct fields from a WorkflowRun object.
-// It uses the global 'workflows' cache for 'workflow_name'.
+// directFieldNames are the fields resolved by getFieldValue. Keep in sync with its switch.
+var directFieldNames = []string{
+	"repo", "run_id", "node_id", "head_branch", "head_sha", "path", "run_number", "run_attempt", "event",
+	"display_title", "status", "conclusion", "conclusion_bucket", "workflow_id", "workflow_name", "pr_number", "actor_login",
+	"triggering_actor_login", "created_at_unix", "updated_at_unix", "run_started_at_unix",
+}
+
+// derivedFieldNames are the fields resolved in getWorkflowRunsFromGithub from several run attributes.
+var derivedFieldNames = []string{"derived_target_branch", "derived_commit_pr_title", "dispatch_input"}
+
+// parseWorkflowFields splits a comma-separated field list (EXPORT_FIELDS_WORKFLOW_RUN) into the label
+// names of the workflow run metrics. Labels are registered in the configured order and every label value is
+// resolved by name, so any order works, but each field must be known and appear only once.
+// The capture groups of PATH_DERIVED_LABEL_REGEX are appended to the configured fields.
+func parseWorkflowFields(workflowFields string) ([]string, error) {
+	knownFields := make(map[string]bool)
+	for _, fieldName := range append(append([]string{}, directFieldNames...), derivedFieldNames...) {
+		knownFields[fieldName] = true
+	}
+
+	var fieldNames, unknownFields []string
+	seenFields := make(map[string]bool)
+	for _, fieldName := range strings.Split(workflowFields, ",") {
+		fieldName = strings.TrimSpace(fieldName)
+		if fieldName == "" {
+			continue
+		}
+		if seenFields[fieldName] {
+			return nil, fmt.Errorf("field %q is listed more than once", fieldName)
+		}
+		seenFields[fieldName] = true
+		if !knownFields[fieldName] {
+			unknownFields = append(unknownFields, fieldName)
+		}
+		fieldNames = append(fieldNames, fieldName)
+	}
+
+	if len(unknownFields) > 0 {
+		return nil, fmt.Errorf("unknown field(s) %s, supported fields are %s",
+			strings.Join(unknownFields, ","), strings.Join(append(append([]string{}, directFieldNames...), derivedFieldNames...), ","))
+	}
+	if len(fieldNames) == 0 {
+		return nil, fmt.Errorf("no field configured")
+	}
+	return append(fieldNames, getPathDerivedLabelNames(pathDerivedLabelPattern)...), nil
+}
+
+// getFieldValue extracts basic, direct fields from a WorkflowRun object, replacing values left out of
+// LABEL_VALUE_ALLOWLIST. It uses the global 'workflows' cache for 'workflow_name'.
 func getFieldValue(repoFullName string, run github.WorkflowRun, fieldName string) string {
+	return allowLabelValue(fieldName, getRawFieldValue(repoFullName, run, fieldName))
+}
+
+// getRawFieldValue extracts a direct field from a WorkflowRun object without applying LABEL_VALUE_ALLOWLIST.
+func getRawFieldValue(repoFullName string, run github.WorkflowRun, fieldName string) string {
 	switch fieldName {
 	case "repo":
 		return repoFullName
@@ -64,17 +118,17 @@ func getFieldValue(repoFullName string, run github.WorkflowRun, fieldName string
 		return getSafeString(run.Status)
 	case "conclusion":
 		return getSafeString(run.Conclusion)
+	case "conclusion_bucket":
+		return getConclusionBucket(getSafeString(run.Conclusion))
 	case "workflow_id":
 		return strconv.FormatInt(getSafeInt64(run.WorkflowID), 10)
 	case "workflow_name": // Uses the global 'workflows' cache
-		if repoWorkflows, repoCacheExists := workflows[repoFullName]; repoCacheExists {
-			if wf, wfExists := repoWorkflows[getSafeInt64(run.WorkflowID)]; wfExists && wf != nil && wf.Name != nil {
-				return *wf.Name
-			}
+		if wf := getCachedWorkflow(repoFullName, getSafeInt64(run.WorkflowID)); wf != nil && wf.Name != nil {
+			return *wf.Name
 		}
 		// log.Printf("Workflow name not found in cache for repo '%s', workflow_id '%d'", repoFullName, getSafeInt64(run.WorkflowID))
 		return "unknown_workflow_name" // Default if not found
-	case "pr_number": // Primarily derived in main loop; this is a fallback if requested directly
+	case "pr_number": // Overridden in main loop for push runs when RESOLVE_PR_FROM_COMMIT is enabled
 		if len(run.PullRequests) > 0 && run.PullRequests[0] != nil && run.PullRequests[0].Number != nil {
 			return strconv.Itoa(*run.PullRequests[0].Number)
 		}
@@ -100,19 +154,108 @@ func getFieldValue(repoFullName string, run github.WorkflowRun, fieldName string
 		}
 		return "0"
 	case "run_started_at_unix":
-		if run.RunStartedAt != nil && !run.RunStartedAt.IsZero() {
-			return strconv.FormatInt(run.RunStartedAt.Time.Unix(), 10)
+		if startedAt, ok := getRunStartTime(&run); ok {
+			return strconv.FormatInt(startedAt.Unix(), 10)
 		}
 		return "0"
-	// "derived_target_branch" and "derived_commit_pr_title" are handled by the caller.
+		// "derived_target_branch", "derived_commit_pr_title" and "dispatch_input" are handled by the caller.
 	}
 	// log.Printf("Field '%s' not handled by getFieldValue or is a derived field.", fieldName)
 	return "" // Return empty for unhandled direct fields
 }
 
+// getRunNumericStatus maps the status and conclusion of a run to the value of github_workflow_run_status.
+func getRunNumericStatus(run *github.WorkflowRun) float64 {
+	return getNumericStatus(getSafeString(run.Status), getSafeString(run.Conclusion))
+}
+
+// getNumericStatus maps a status and conclusion (of a run or check run) to the value of github_workflow_run_status.
+func getNumericStatus(runStatus string, runConclusion string) float64 {
+	numericStatus := config.Metrics.DefaultStatusValue // Default for unknown or other states, 99 unless overridden
+
+	if runStatus == "completed" {
+		switch runConclusion {
+		case "success":
+			numericStatus = 1
+		case "failure":
+			numericStatus = config.Metrics.FailureStatusValue // Not 0 by default, which dashboards can't tell from missing data
+		case "cancelled":
+			numericStatus = 5
+		case "skipped":
+			numericStatus = 2
+		case "neutral":
+			numericStatus = 6
+		case "timed_out":
+			numericStatus = 7
+		default:
+			numericStatus = 8 // Unknown conclusion for a completed run
+		}
+	} else if runStatus == "in_progress" || runStatus == "requested" || runStatus == "waiting" {
+		numericStatus = 3
+	} else if runStatus == "queued" {
+		numericStatus = 4
+	} else if runStatus == "action_required" { // GitHub AE status
+		numericStatus = 9
+	} else if runStatus == "stale" { // Workflow runs that have not been updated in 7 days.
+		numericStatus = 10
+	}
+	// numericStatus will remain DEFAULT_STATUS_VALUE if no specific mapping is found.
+	return numericStatus
+}
+
+// getDefaultBranch returns the default branch of a repository from repoMetadata, or "" when unknown.
+func getDefaultBranch(repoFullName string) string {
+	if repo, ok := getRepoMetadata(repoFullName); ok {
+		return repo.GetDefaultBranch()
+	}
+	return ""
+}
+
+// filterDefaultBranchRuns keeps the runs on the default branch of a repository (DEFAULT_BRANCH_ONLY).
+// Runs are kept unfiltered when the default branch is unknown.
+func filterDefaultBranchRuns(repoFullName string, runs []*github.WorkflowRun) []*github.WorkflowRun {
+	defaultBranch := getDefaultBranch(repoFullName)
+	if defaultBranch == "" {
+		return runs
+	}
+	var filtered []*github.WorkflowRun
+	for _, run := range runs {
+		if run != nil && run.GetHeadBranch() == defaultBranch {
+			filtered = append(filtered, run)
+		}
+	}
+	return filtered
+}
+
+// isStaleRun reports whether GitHub marked a run stale, having not updated it in 7 days.
+func isStaleRun(run *github.WorkflowRun) bool {
+	return run.GetStatus() == "stale" || run.GetConclusion() == "stale"
+}
+
+// filterStaleRuns drops the stale runs (DROP_STALE_RUNS).
+func filterStaleRuns(runs []*github.WorkflowRun) []*github.WorkflowRun {
+	var filtered []*github.WorkflowRun
+	for _, run := range runs {
+		if run != nil && !isStaleRun(run) {
+			filtered = append(filtered, run)
+		}
+	}
+	return filtered
+}
+
+// getFetchWindowStart returns the creation time before which workflow runs and deployments are no longer fetched.
+func getFetchWindowStart() time.Time {
+	fetchHours := config.Github.FetchMaxWorkflowCreationAgeHours
+	if fetchHours <= 0 {
+		fetchHours = 12
+	}
+	return time.Now().Add(-time.Duration(fetchHours) * time.Hour)
+}
+
 // getWorkflowRunsToFetchFromRepo fetches workflow runs for a single repository
-// based on the configured creation age lookback.
-func getWorkflowRunsToFetchFromRepo(owner string, repoName string) []*github.WorkflowRun {
+// based on the configured creation age lookback. It also reports whether all pages were fetched;
+// when false the runs are partial and must not replace previously exported values.
+func getWorkflowRunsToFetchFromRepo(owner string, repoName string) ([]*github.WorkflowRun, bool) {
 	fetchHours := config.Github.FetchMaxWorkflowCreationAgeHours
 	if fetchHours <= 0 {
 		fetchHours = 12 // Default to 12 hours if not configured or invalid
@@ -123,42 +266,223 @@ func getWorkflowRunsToFetchFromRepo(owner string, repoName string) []*github.Wor
 		fetchHours = -fetchHours
 	}
 
-	windowStart := time.Now().Add(time.Duration(fetchHours) * time.Hour).Format(time.RFC3339)
+	windowStart := time.Now().Add(time.Duration(fetchHours) * time.Hour)
 	// log.Printf("Fetching workflow runs for %s/%s created since %s", owner, repoName, windowStart)
+	listWindowStart := windowStart
+	if widenedStart, ok := getRecentlyCompletedCreationStart(); ok && widenedStart.Before(windowStart) {
+		listWindowStart = widenedStart
+	}
 
 	listOptions := &github.ListWorkflowRunsOptions{
-		ListOptions: github.ListOptions{PerPage: 100}, // Maximize items per page
-		Created:     ">=" + windowStart,              // Filter by creation date
+		ListOptions: github.ListOptions{PerPage: getPerPage()},
+		Created:     ">=" + listWindowStart.Format(time.RFC3339), // Filter by creation date
+	}
+	if config.Github.DefaultBranchOnly {
+		listOptions.Branch = getDefaultBranch(owner + "/" + repoName) // Empty (no filter) when unknown
+	}
+
+	var runs []*github.WorkflowRun
+	var complete bool
+	if config.Github.FetchStrategy == fetchStrategySearch {
+		runs, complete = getSearchedWorkflowRunsFromRepo(owner, repoName, listOptions)
+	} else {
+		runs, complete = listWorkflowRunsFromRepo(owner, repoName, "", listOptions)
+	}
+	if listWindowStart.Before(windowStart) {
+		runs = filterRecentlyCompletedRuns(runs, windowStart)
+	}
+	return runs, complete
+}
+
+// getRecentlyCompletedCreationStart returns the creation time from which runs are listed to find the ones completed
+// within FETCH_RECENTLY_COMPLETED_HOURS: a run completing in that window started at most
+// MAX_PLAUSIBLE_RUN_DURATION_HOURS (72 when disabled) before it. It returns false when the option is disabled.
+func getRecentlyCompletedCreationStart() (time.Time, bool) {
+	completedHours := config.Github.FetchRecentlyCompletedHours
+	if completedHours <= 0 {
+		return time.Time{}, false
+	}
+	maxRunHours := config.Metrics.MaxPlausibleRunDurationHours
+	if maxRunHours <= 0 {
+		maxRunHours = 72
+	}
+	return time.Now().Add(-time.Duration(completedHours+maxRunHours) * time.Hour), true
+}
+
+// filterRecentlyCompletedRuns keeps the runs created since windowStart, and the older ones of the widened
+// creation window completed within FETCH_RECENTLY_COMPLETED_HOURS. UpdatedAt is the completion time of
+// completed runs.
+func filterRecentlyCompletedRuns(runs []*github.WorkflowRun, windowStart time.Time) []*github.WorkflowRun {
+	completedSince := time.Now().Add(-time.Duration(config.Github.FetchRecentlyCompletedHours) * time.Hour)
+	var filtered []*github.WorkflowRun
+	for _, run := range runs {
+		if run == nil {
+			continue
+		}
+		if !run.GetCreatedAt().Time.Before(windowStart) ||
+			(run.GetStatus() == "completed" && !run.GetUpdatedAt().Time.Before(completedSince)) {
+			filtered = append(filtered, run)
+		}
 	}
+	return filtered
+}
 
+// getSearchedWorkflowRunsFromRepo fetches the runs of the workflows of a repository found by the code search
+// (FETCH_STRATEGY=search), newest first like ListRepositoryWorkflowRuns. Repositories without any of them cost no call.
+func getSearchedWorkflowRunsFromRepo(owner string, repoName string, listOptions *github.ListWorkflowRunsOptions) ([]*github.WorkflowRun, bool) {
 	var allRuns []*github.WorkflowRun
+	for _, workflowFile := range getSearchedWorkflowFiles(owner + "/" + repoName) {
+		workflowListOptions := *listOptions
+		runs, complete := listWorkflowRunsFromRepo(owner, repoName, workflowFile, &workflowListOptions)
+		allRuns = append(allRuns, runs...)
+		if !complete {
+			return allRuns, false
+		}
+	}
+	sort.SliceStable(allRuns, func(i, j int) bool {
+		return allRuns[i].GetCreatedAt().After(allRuns[j].GetCreatedAt().Time)
+	})
+	return allRuns, true
+}
+
+// listWorkflowRunsFromRepo pages through the runs of a repository, or of one of its workflows when
+// workflowFile is set. See getWorkflowRunsToFetchFromRepo for the completeness flag.
+func listWorkflowRunsFromRepo(owner string, repoName string, workflowFile string, listOptions *github.ListWorkflowRunsOptions) ([]*github.WorkflowRun, bool) {
+	var allRuns []*github.WorkflowRun
+	call, target := "ListRepositoryWorkflowRuns", owner+"/"+repoName
+	if workflowFile != "" {
+		call, target = "ListWorkflowRunsByFileName", fmt.Sprintf("%s/%s (workflow %s)", owner, repoName, workflowFile)
+	}
 	for {
-		runsResponse, httpResp, err := client.Actions.ListRepositoryWorkflowRuns(context.Background(), owner, repoName, listOptions)
+		var runsResponse *github.WorkflowRuns
+		var httpResp *github.Response
+		var err error
+		if workflowFile != "" {
+			runsResponse, httpResp, err = getClient().Actions.ListWorkflowRunsByFileName(fetcherContext(fetcherRuns), owner, repoName, workflowFile, listOptions)
+		} else {
+			runsResponse, httpResp, err = getClient().Actions.ListRepositoryWorkflowRuns(fetcherContext(fetcherRuns), owner, repoName, listOptions)
+		}
+		recordAPIError(err)
 		if rlErr, ok := err.(*github.RateLimitError); ok {
-			log.Printf("ListRepositoryWorkflowRuns ratelimited for %s/%s. Pausing until %s", owner, repoName, rlErr.Rate.Reset.Time.String())
-			time.Sleep(time.Until(rlErr.Rate.Reset.Time))
+			log.Printf("%s ratelimited for %s. Pausing until %s", call, target, rlErr.Rate.Reset.Time.String())
+			sleepUntilRateLimitReset(rlErr.Rate.Reset.Time)
 			continue // Retry current page
 		} else if err != nil {
-			log.Printf("ListRepositoryWorkflowRuns error for repo %s/%s: %v", owner, repoName, err)
-			return allRuns // Return what was fetched successfully before the error
+			log.Printf("%s error for repo %s: %v", call, target, err)
+			return allRuns, false // Return what was fetched successfully before the error
 		}
 
 		if runsResponse != nil && runsResponse.WorkflowRuns != nil {
 			allRuns = append(allRuns, runsResponse.WorkflowRuns...)
 		}
 
-		if httpResp.NextPage == 0 {
+		recordPageFetched(httpResp)
+		if getNextPage(httpResp) == 0 {
 			break
 		}
-		listOptions.Page = httpResp.NextPage
+		listOptions.Page = getNextPage(httpResp)
+	}
+	return allRuns, true
+}
+
+// getRunStartTime returns the RunStartedAt of a run. When it is missing, it falls back to CreatedAt
+// if USE_CREATED_AS_START_FALLBACK is set, which overestimates durations by the queuing time.
+func getRunStartTime(run *github.WorkflowRun) (time.Time, bool) {
+	if run.RunStartedAt != nil && !run.RunStartedAt.IsZero() {
+		return run.RunStartedAt.Time, true
+	}
+	if config.Metrics.UseCreatedAsStartFallback && run.CreatedAt != nil && !run.CreatedAt.IsZero() {
+		return run.CreatedAt.Time, true
+	}
+	return time.Time{}, false
+}
+
+// getEstimatedRunDurationMs estimates the duration of a terminal run from its start time (see getRunStartTime) and UpdatedAt.
+// This is less accurate than the usage API, especially for re-runs or if UpdatedAt changes for other reasons.
+// It returns -1 when no estimate is possible, or when it exceeds MAX_PLAUSIBLE_RUN_DURATION_HOURS.
+func getEstimatedRunDurationMs(run *github.WorkflowRun) float64 {
+	runStatus := getSafeString(run.Status)
+	startedAt, started := getRunStartTime(run)
+	if runStatus == "completed" && run.GetConclusion() != "" && // Only when UpdatedAt marks the completion, not for stale runs
+		started &&
+		run.UpdatedAt != nil && !run.UpdatedAt.IsZero() &&
+		run.UpdatedAt.Time.After(startedAt) && // Sanity check
+		(run.CreatedAt == nil || !startedAt.Before(run.CreatedAt.Time)) {
+		estimated := run.UpdatedAt.Time.Sub(startedAt)
+		if maxHours := config.Metrics.MaxPlausibleRunDurationHours; maxHours > 0 && estimated > time.Duration(maxHours)*time.Hour {
+			return -1 // UpdatedAt changed after the completion, e.g. on a comment
+		}
+		return float64(estimated.Milliseconds())
+	}
+	return -1
+}
+
+// getWorkflowRunDurationMs returns the duration of a run in milliseconds, or -1 if unknown, along with
+// its usage when it was fetched (nil otherwise).
+// The precise duration comes from the usage API, which is skipped for runs estimated to be shorter
+// than USAGE_MIN_ESTIMATED_DURATION_SECONDS to save API quota.
+func getWorkflowRunDurationMs(owner string, repoName string, run *github.WorkflowRun) (float64, *github.WorkflowRunUsage) {
+	if isStaleRun(run) {
+		return -1, nil // Its timestamps and usage say nothing about how long it ran
+	}
+	estimatedMs := getEstimatedRunDurationMs(run)
+	minEstimatedMs := float64(config.Metrics.UsageMinEstimatedDurationSeconds * 1000)
+	if estimatedMs >= 0 && estimatedMs < minEstimatedMs {
+		return estimatedMs, nil
+	}
+
+	// Note: GetWorkflowRunUsageByID can be rate-limited or return 404 if timing info not ready.
+	runUsage, _, errUsage := getClient().Actions.GetWorkflowRunUsageByID(fetcherContext(fetcherRuns), owner, repoName, getSafeInt64(run.ID))
+	recordAPIError(errUsage)
+	if errUsage != nil {
+		// Optionally log GetWorkflowRunUsageByID error if it wasn't a simple 404 (not ready)
+		// if !strings.Contains(errUsage.Error(), "404") {
+		// log.Printf("GetWorkflowRunUsageByID error for run %d (%s/%s): %v. Used fallback duration.", getSafeInt64(run.ID), owner, repoName, errUsage)
+		// }
+		return estimatedMs, nil
+	}
+	if runUsage != nil && runUsage.RunDurationMS != nil {
+		return float64(getSafeInt64(runUsage.RunDurationMS)), runUsage
+	}
+	return estimatedMs, runUsage
+}
+
+// isDurationExcluded reports whether the conclusion of a run is in DURATION_EXCLUDE_CONCLUSIONS.
+// The usage of such runs is still fetched for their billable time.
+func isDurationExcluded(run *github.WorkflowRun) bool {
+	conclusion := run.GetConclusion()
+	if conclusion == "" {
+		return false
+	}
+	for _, excluded := range config.Metrics.DurationExcludeConclusions.Value() {
+		if strings.EqualFold(strings.TrimSpace(excluded), conclusion) {
+			return true
+		}
+	}
+	return false
+}
+
+// addRunBillableSeconds adds the billable time of a run, per runner OS, to billableSeconds.
+// The billable map is nil for runs without billable time (e.g. public repositories or self-hosted runners).
+func addRunBillableSeconds(billableSeconds map[runOSKey]float64, repoFullName string, workflowName string, runUsage *github.WorkflowRunUsage) {
+	if runUsage == nil {
+		return
+	}
+	billMap := runUsage.GetBillable()
+	if billMap == nil || *billMap == nil {
+		return
+	}
+	for os, bill := range *billMap {
+		if bill == nil || bill.TotalMS == nil {
+			continue
+		}
+		billableSeconds[runOSKey{repoFullName, workflowName, os}] += float64(bill.GetTotalMS()) / 1000
 	}
-	// log.Printf("Fetched %d workflow runs for %s/%s created since %s", len(allRuns), owner, repoName, windowStart)
-	return allRuns
 }
 
 // getWorkflowRunsFromGithub is the main goroutine for fetching and processing workflow run metrics.
 func getWorkflowRunsFromGithub() {
-	if client == nil {
+	if getClient() == nil {
 		log.Println("Error in getWorkflowRunsFromGithub: GitHub client is not initialized.")
 		return
 	}
@@ -167,142 +491,391 @@ func getWorkflowRunsFromGithub() {
 		return
 	}
 
-	// Cache the split field names from config for minor efficiency inside the loop.
-	configuredFieldNames := strings.Split(config.WorkflowFields, ",")
-	if len(configuredFieldNames) == 0 {
-		log.Println("Error: config.WorkflowFields resulted in zero labels. Cannot proceed with getWorkflowRunsFromGithub.")
-		return
-	}
-
-
-	refreshTicker := time.NewTicker(time.Duration(config.Github.Refresh) * time.Second)
+	refreshInterval := time.Duration(config.Github.Refresh) * time.Second
+	log.Printf("getWorkflowRunsFromGithub will refresh every %v for %d repositories", refreshInterval, len(repositories))
+	expectFetcherCycles("getWorkflowRunsFromGithub")
+	sleepStartupJitter("getWorkflowRunsFromGithub")
+	refreshTicker := time.NewTicker(refreshInterval)
 	defer refreshTicker.Stop()
+	var cycleDurations cycleDurationTracker
+	// Status and duration series are replaced per repository rather than reset, see getWorkflowRunsToFetchFromRepo.
+	runSeries := newRepoSeriesTracker(workflowRunStatusGauge, workflowRunDurationGauge, workflowRunDurationSecondsGauge)
+	repoAggregates := newRepoAggregateTracker()
 
 	for range refreshTicker.C {
-		log.Printf("Starting workflow run collection cycle for %d repositories.", len(repositories))
-		workflowRunStatusGauge.Reset() // Clear all previously set statuses for all series
-		if config.Metrics.FetchWorkflowRunUsage && workflowRunDurationGauge != nil {
-			workflowRunDurationGauge.Reset()
+		sleepTickJitter()
+		if isCollectionPaused() {
+			continue
+		}
+		clientGenerationBefore := getClientGeneration()
+		fetchDuration := collectWorkflowRuns(refreshInterval, runSeries, repoAggregates)
+		if getClientGeneration() != clientGenerationBefore {
+			log.Println("GitHub client was rebuilt during the workflow run collection cycle. Retrying the cycle with the new credentials.")
+			fetchDuration = collectWorkflowRuns(0, runSeries, repoAggregates) // Not paced, the cycle is already late
+		}
+		pushToPushgateway()
+		writeTextfile()
+
+		// --- Check the refresh interval is long enough for the number of repositories ---
+		observeCycleDuration("getWorkflowRunsFromGithub", fetchDuration, refreshInterval)
+		cycleDurations.observe(fetchDuration, len(repositories))
+		if minimumRefresh := cycleDurations.minimumRefresh(len(repositories)); minimumRefresh > refreshInterval {
+			if config.Github.AutoTuneRefresh {
+				log.Printf("Workflow run collection cycles for %d repositories need about %v, longer than the %v refresh. Adjusting refresh to %v.",
+					len(repositories), minimumRefresh, refreshInterval, minimumRefresh)
+				refreshInterval = minimumRefresh
+				refreshTicker.Reset(refreshInterval)
+			} else {
+				log.Printf("Warning: workflow run collection cycles for %d repositories need about %v, longer than the %v refresh (GITHUB_REFRESH). "+
+					"Metrics will lag; increase GITHUB_REFRESH or set AUTO_TUNE_REFRESH=true.", len(repositories), minimumRefresh, refreshInterval)
+			}
+		}
+	} // End ticker loop
+}
+
+// collectWorkflowRuns runs a single workflow run collection cycle over all repositories, spreading the
+// repository fetches across refreshInterval when SPREAD_REPO_FETCHES is enabled. It returns the time spent fetching, pacing excluded.
+// Repositories not fetched completely keep their last known series in runSeries and aggregates in repoAggregates.
+func collectWorkflowRuns(refreshInterval time.Duration, runSeries *repoSeriesTracker, repoAggregates *repoAggregateTracker) time.Duration {
+	// Held for the whole cycle, so that ReloadWorkflowFields swaps the gauges between cycles.
+	workflowRunGaugesMu.RLock()
+	defer workflowRunGaugesMu.RUnlock()
+	runSeries.useGauges(workflowRunStatusGauge, workflowRunDurationGauge, workflowRunDurationSecondsGauge)
+
+	// Field names validated by InitMetrics, in the order the gauges were registered with.
+	configuredFieldNames := workflowRunFieldNames
+
+	cycleStart := time.Now()
+	log.Printf("Starting workflow run collection cycle for %d repositories.", len(repositories))
+	webhookRuns.prune(repositories, getFetchWindowStart())
+	traces := newTraceBatch()
+	pacer := newRepoPacer(refreshInterval, len(repositories))
+	var pacingWait time.Duration
+
+	processedRepos := 0
+	attemptedRepos := 0 // Repositories whose runs were listed, successfully or not
+	budget := newRunBudget(config.Metrics.MaxRunsPerCycle)
+	reposToFetch := scheduleRepositories(rotateRepositories(repositories, budget))
+	for i, repoFullName := range reposToFetch {
+		if isCycleOverdue(time.Since(cycleStart)-pacingWait, refreshInterval) {
+			log.Printf("Workflow run collection cycle exceeded %v, carrying %d repositories over to the next cycle.", refreshInterval, len(reposToFetch)-i)
+			for _, carried := range reposToFetch[i:] {
+				runSeries.keepRepo(carried) // Their last known metrics still apply
+				repoAggregates.keepRepo(carried)
+			}
+			carryOverRepositories(reposToFetch[i:])
+			break
+		}
+		pacingWait += pacer.wait()
+		ownerAndRepo := strings.Split(repoFullName, "/")
+		if len(ownerAndRepo) != 2 {
+			log.Printf("Invalid repository format '%s' in getWorkflowRunsFromGithub. Skipping.", repoFullName)
+			continue
+		}
+		owner, repoName := ownerAndRepo[0], ownerAndRepo[1]
+
+		if config.Github.SkipReposWithoutWorkflows && hasNoWorkflows(repoFullName) {
+			continue // No workflows, so no runs to list
+		}
+		if config.Github.FetchStrategy == fetchStrategySearch && len(getSearchedWorkflowFiles(repoFullName)) == 0 {
+			continue // None of SEARCH_WORKFLOW_FILES, so no runs to list
+		}
+		if !ensureWorkflowsForRepo(owner, repoName) {
+			log.Printf("Workflow definitions of %s are not cached yet. Deferring its runs to the next cycle.", repoFullName)
+			runSeries.keepRepo(repoFullName)
+			repoAggregates.keepRepo(repoFullName)
+			continue
+		}
+		if !shouldPollRepo(repoFullName) {
+			runSeries.keepRepo(repoFullName) // Dormant, its last known metrics still apply
+			repoAggregates.keepRepo(repoFullName)
+			continue
+		}
+
+		fetchedRuns, complete := getWorkflowRunsForRepo(owner, repoName)
+		attemptedRepos++
+		if !complete {
+			log.Printf("Workflow runs of %s were only partially fetched. Keeping its last known metrics.", repoFullName)
+			runSeries.keepRepo(repoFullName)
+			repoAggregates.keepRepo(repoFullName)
+			continue
+		}
+		if canonicalName := getRunsRepoFullName(fetchedRuns); canonicalName != "" && canonicalName != repoFullName {
+			recordRepoRename(repoFullName, canonicalName)
+			runSeries.replaceRepo(repoFullName) // Drops the series exported under the previous name
+			repoFullName = canonicalName
+			owner, repoName = splitRepoFullName(canonicalName)
+			ensureWorkflowsForRepo(owner, repoName) // Cached under the previous name until the next refresh
 		}
+		setRepoLastFetch(repoFullName)
+		recordRepoRuns(repoFullName, len(fetchedRuns))
+		runSeries.replaceRepo(repoFullName)
+		aggregates := repoAggregates.replaceRepo(repoFullName)
+		processedRepos++
+		recordWorkflowRunUpdates(repoFullName, fetchedRuns) // Before filtering, other branches are billed too
+		if config.Github.DefaultBranchOnly {
+			fetchedRuns = filterDefaultBranchRuns(repoFullName, fetchedRuns)
+		}
+		if config.Metrics.DropStaleRuns {
+			fetchedRuns = filterStaleRuns(fetchedRuns)
+		}
+		countConcurrencyCancellations(repoFullName, fetchedRuns)
+		countCreatedRuns(repoFullName, fetchedRuns)
+		countMissingStartTimeRuns(repoFullName, fetchedRuns)
+		recordScheduledRuns(repoFullName, fetchedRuns)
+		recordWorkflowActivity(repoFullName, fetchedRuns)
+		aggregates.activeWorkflows.addRepo(repoFullName)
 
-		for _, repoFullName := range repositories {
-			ownerAndRepo := strings.Split(repoFullName, "/")
-			if len(ownerAndRepo) != 2 {
-				log.Printf("Invalid repository format '%s' in getWorkflowRunsFromGithub. Skipping.", repoFullName)
+		repoShare := budget.repoShare(len(reposToFetch) - i)
+		exportedRuns := 0
+		for _, run := range fetchedRuns {
+			if run == nil || run.ID == nil { // Basic safety check
+				continue
+			}
+			aggregates.latestRuns.add(repoFullName, getFieldValue(repoFullName, *run, "workflow_name"), run) // From all runs, sampled or not
+			aggregates.reusableCallers.add(repoFullName, getFieldValue(repoFullName, *run, "workflow_name"), run)
+			aggregates.latestCompleted.add(repoFullName, getFieldValue(repoFullName, *run, "workflow_name"), run)
+			aggregates.activeWorkflows.add(repoFullName, run)
+			aggregates.startDelays.add(repoFullName, getFieldValue(repoFullName, *run, "workflow_name"), run)
+			if !isRunSampled(repoFullName, run.GetID()) {
+				continue
+			}
+			if repoShare >= 0 && exportedRuns >= repoShare { // Runs are listed newest first
+				budget.skip(repoFullName)
 				continue
 			}
-			owner, repoName := ownerAndRepo[0], ownerAndRepo[1]
+			exportedRuns++
+			traces.addRun(repoFullName, getFieldValue(repoFullName, *run, "workflow_name"), run)
 
-			fetchedRuns := getWorkflowRunsToFetchFromRepo(owner, repoName)
+			// --- Derive Complex Fields ---
+			var derivedTargetBranch string
+			event := getSafeString(run.Event)
 
-			for _, run := range fetchedRuns {
-				if run == nil || run.ID == nil { // Basic safety check
-					continue
-				}
+			if event == "pull_request" && len(run.PullRequests) > 0 && run.PullRequests[0] != nil &&
+				run.PullRequests[0].Base != nil && run.PullRequests[0].Base.Ref != nil {
+				derivedTargetBranch = *run.PullRequests[0].Base.Ref
+			} else if run.HeadBranch != nil {
+				// For 'push', HeadBranch is the branch pushed to.
+				// For 'workflow_dispatch', HeadBranch is the branch the workflow definition runs on.
+				// The actual "target" for a dispatch might be an input, not directly in the run object.
+				// HeadBranch is a reasonable default here.
+				derivedTargetBranch = *run.HeadBranch
+			}
+			// If derivedTargetBranch is still empty, it will be an empty label.
 
-				// --- Derive Complex Fields ---
-				var derivedTargetBranch string
-				event := getSafeString(run.Event)
-
-				if event == "pull_request" && len(run.PullRequests) > 0 && run.PullRequests[0] != nil &&
-					run.PullRequests[0].Base != nil && run.PullRequests[0].Base.Ref != nil {
-					derivedTargetBranch = *run.PullRequests[0].Base.Ref
-				} else if run.HeadBranch != nil {
-					// For 'push', HeadBranch is the branch pushed to.
-					// For 'workflow_dispatch', HeadBranch is the branch the workflow definition runs on.
-					// The actual "target" for a dispatch might be an input, not directly in the run object.
-					// HeadBranch is a reasonable default here.
-					derivedTargetBranch = *run.HeadBranch
-				}
-				// If derivedTargetBranch is still empty, it will be an empty label.
-
-				var derivedCommitPrTitle string
-				if event == "pull_request" && len(run.PullRequests) > 0 && run.PullRequests[0] != nil &&
-					run.PullRequests[0].Title != nil {
-					derivedCommitPrTitle = *run.PullRequests[0].Title
-				} else if run.DisplayTitle != nil && *run.DisplayTitle != "" { // Use DisplayTitle (v72) if available
-					derivedCommitPrTitle = *run.DisplayTitle
-				} else if run.HeadCommit != nil && run.HeadCommit.Message != nil {
-					// Use the first line of the head commit message as a fallback
-					messageLines := strings.SplitN(*run.HeadCommit.Message, "\n", 2)
-					derivedCommitPrTitle = strings.TrimSpace(messageLines[0])
-				}
-				// If derivedCommitPrTitle is still empty, it will be an empty label.
-
-
-				// --- Determine Numeric Status (based on run.Status and run.Conclusion) ---
-				var numericStatus float64 = 99 // Default for unknown or other states
-				runStatus := getSafeString(run.Status)
-				runConclusion := getSafeString(run.Conclusion)
-
-				if runStatus == "completed" {
-					switch runConclusion {
-					case "success": numericStatus = 1
-					case "failure": numericStatus = 0
-					case "cancelled": numericStatus = 5
-					case "skipped": numericStatus = 2
-					case "neutral": numericStatus = 6
-					case "timed_out": numericStatus = 7
-					default: numericStatus = 8 // Unknown conclusion for a completed run
+			derivedCommitPrTitle := deriveCommitPrTitle(run)
+			// If derivedCommitPrTitle is still empty, it will be an empty label.
+
+			derivedPrNumber := getFieldValue(repoFullName, *run, "pr_number")
+			if event == "push" && config.Metrics.ResolvePRFromCommit {
+				// Push runs carry no pull request; resolve it from the head commit (e.g. merge queues).
+				aggregates.headSHAs[getSafeString(run.HeadSHA)] = true
+				if pr := getPullRequestForCommit(owner, repoName, getSafeString(run.HeadSHA)); pr != nil {
+					derivedPrNumber = strconv.Itoa(pr.GetNumber())
+					if pr.GetTitle() != "" && config.Metrics.CommitTitleMode != commitTitleModeNone {
+						derivedCommitPrTitle = formatCommitTitle(pr.GetTitle())
 					}
-				} else if runStatus == "in_progress" || runStatus == "requested" || runStatus == "waiting" {
-					numericStatus = 3
-				} else if runStatus == "queued" {
-					numericStatus = 4
-				} else if runStatus == "action_required" { // GitHub AE status
-					numericStatus = 9
-				} else if runStatus == "stale" { // Workflow runs that have not been updated in 7 days.
-					numericStatus = 10
 				}
-				// numericStatus will remain 99 if no specific mapping is found.
-
-				// --- Construct Label Values in the exact order defined by config.WorkflowFields ---
-				labelValues := make([]string, len(configuredFieldNames))
-				for i, fieldName := range configuredFieldNames {
-					var val string
-					switch fieldName {
-					case "derived_target_branch":
-						val = derivedTargetBranch
-					case "derived_commit_pr_title":
-						val = derivedCommitPrTitle
-					default:
+			}
+
+			// --- Determine Numeric Status (based on run.Status and run.Conclusion) ---
+			numericStatus := getRunNumericStatus(run)
+			runStatus := getSafeString(run.Status)
+
+			// --- Construct Label Values in the order the gauges were registered with ---
+			pathLabels := getPathDerivedLabels(getSafeString(run.Path))
+			labelValues := make([]string, len(configuredFieldNames))
+			for i, fieldName := range configuredFieldNames {
+				var val string
+				switch fieldName {
+				case "derived_target_branch":
+					val = derivedTargetBranch
+				case "derived_commit_pr_title":
+					val = derivedCommitPrTitle
+				case "pr_number":
+					val = derivedPrNumber
+				case "dispatch_input":
+					val = getDispatchInput(owner, repoName, run)
+				default:
+					if pathLabel, ok := pathLabels[fieldName]; ok {
+						val = pathLabel
+					} else {
 						val = getFieldValue(repoFullName, *run, fieldName)
 					}
-					labelValues[i] = val
 				}
+				labelValues[i] = allowLabelValue(fieldName, val) // Derived fields bypass getFieldValue
+				if labelValues[i] == "" && config.Metrics.EmptyLabelPlaceholder != "" {
+					labelValues[i] = config.Metrics.EmptyLabelPlaceholder
+				}
+			}
 
-				workflowRunStatusGauge.WithLabelValues(labelValues...).Set(numericStatus)
+			workflowRunStatusGauge.WithLabelValues(labelValues...).Set(numericStatus)
+			runSeries.add(repoFullName, labelValues)
+			if config.Metrics.FetchCheckRuns {
+				aggregates.checkRuns.add(owner, repoName, repoFullName, getSafeString(run.HeadSHA))
+			}
+			aggregates.runIDs[getSafeInt64(run.ID)] = true
+			aggregates.runsPerSHA.add(repoFullName, getFieldValue(repoFullName, *run, "workflow_name"), getSafeString(run.HeadSHA))
+			if runStatus == "pending" { // Waiting for its concurrency group
+				aggregates.concurrencyPending[workflowKey{repoFullName, getFieldValue(repoFullName, *run, "workflow_name")}]++
+			}
+			aggregates.infoRuns = append(aggregates.infoRuns, infoRun{repoFullName, getFieldValue(repoFullName, *run, "workflow_name"), run})
 
-				// --- Handle Workflow Run Duration (if enabled) ---
-				if config.Metrics.FetchWorkflowRunUsage && workflowRunDurationGauge != nil {
-					var durationMs float64 = -1 // Default to -1 if not calculable/fetched
+			// --- Handle runs waiting for a deployment approval ---
+			if runStatus == "waiting" {
+				workflowName := getFieldValue(repoFullName, *run, "workflow_name")
+				for environment, seconds := range getRunWaitingSeconds(owner, repoName, run) {
+					key := workflowRunWaitingKey{repoFullName, workflowName, environment}
+					if seconds > aggregates.waitingSeconds[key] { // Keep the longest-waiting run
+						aggregates.waitingSeconds[key] = seconds
+					}
+				}
+			}
 
-					// Attempt to get precise duration from API first
-					// Note: GetWorkflowRunUsageByID can be rate-limited or return 404 if timing info not ready.
-					runUsage, _, errUsage := client.Actions.GetWorkflowRunUsageByID(context.Background(), owner, repoName, getSafeInt64(run.ID))
-					if errUsage == nil && runUsage != nil && runUsage.RunDurationMS != nil {
-						durationMs = float64(getSafeInt64(runUsage.RunDurationMS))
-					} else {
-						// Fallback: Use RunStartedAt and UpdatedAt (if status is completed/terminal)
-						// This is less accurate, especially for re-runs or if UpdatedAt changes for other reasons.
-						if (runStatus == "completed" || runStatus == "stale") && // Only for terminal states
-							run.RunStartedAt != nil && !run.RunStartedAt.IsZero() &&
-							run.UpdatedAt != nil && !run.UpdatedAt.IsZero() {
-							if run.UpdatedAt.Time.After(run.RunStartedAt.Time) { // Sanity check
-								durationMs = float64(run.UpdatedAt.Time.Sub(run.RunStartedAt.Time).Milliseconds())
-							}
+			// --- Handle Workflow Jobs (if enabled) ---
+			jobsBusySeconds := -1.0 // Unknown
+			if config.Metrics.FetchWorkflowJobs {
+				workflowName := getFieldValue(repoFullName, *run, "workflow_name")
+				runOSes := make(map[string]bool)
+				waitingForHostedRunner := false
+				jobs, jobsFetched := getJobsForRun(owner, repoName, run)
+				traces.addJobs(repoFullName, run, jobs)
+				if busySeconds, ok := getJobsBusySeconds(jobs); ok && runStatus == "completed" {
+					jobsBusySeconds = busySeconds
+				}
+				for _, job := range jobs {
+					if job == nil {
+						continue
+					}
+					runOSes[getJobRunnerOS(job)] = true
+					if isJobWaitingForRunner(job) {
+						aggregates.queuedJobs[getJobRunnerLabels(job)]++
+						if getJobRunnerType(job) == runnerTypeGithubHosted {
+							waitingForHostedRunner = true
 						}
-						// Optionally log GetWorkflowRunUsageByID error if it wasn't a simple 404 (not ready)
-						// if errUsage != nil && !strings.Contains(errUsage.Error(), "404") {
-						// log.Printf("GetWorkflowRunUsageByID error for run %d (%s/%s): %v. Used fallback duration.", getSafeInt64(run.ID), owner, repoName, errUsage)
-						// }
 					}
-					// Uses the same labelValues as workflowRunStatusGauge.
-					// If the duration gauge needs different labels, this part needs adjustment.
+					if job.GetRunnerName() == "" { // Not picked up by a runner (queued, skipped, ...)
+						continue
+					}
+					aggregates.jobRunnerTypes[jobRunnerTypeKey{repoFullName, workflowName, getJobRunnerType(job)}]++
+				}
+				for os := range runOSes { // A run with jobs on several OSes counts once for each
+					aggregates.runOSes[runOSKey{repoFullName, workflowName, os}]++
+				}
+				if waitingForHostedRunner {
+					aggregates.hostedQueueDepths[owner]++
+				}
+				if class := getRunFailureClass(run, jobs, jobsFetched); class != "" {
+					aggregates.failureClasses[runFailureClassKey{repoFullName, workflowName, class}]++
+				}
+			}
+
+			// --- Handle Workflow Run Duration (if enabled) ---
+			if config.Metrics.FetchWorkflowRunUsage && workflowRunDurationGauge != nil {
+				durationMs, runUsage := getWorkflowRunDurationMs(owner, repoName, run)
+				addRunBillableSeconds(aggregates.billableSeconds, repoFullName, getFieldValue(repoFullName, *run, "workflow_name"), runUsage)
+				// Uses the same labelValues as workflowRunStatusGauge.
+				// If the duration gauge needs different labels, this part needs adjustment.
+				if isDurationExcluded(run) {
+					// Drops the series set while the run was still in progress
+					workflowRunDurationGauge.DeleteLabelValues(labelValues...)
+					workflowRunDurationSecondsGauge.DeleteLabelValues(labelValues...)
+				} else {
 					workflowRunDurationGauge.WithLabelValues(labelValues...).Set(durationMs)
+					if durationMs >= 0 { // Unknown durations are omitted instead of using a -1 sentinel
+						workflowRunDurationSecondsGauge.WithLabelValues(labelValues...).Set(durationMs / 1000)
+					}
 				}
-			} // End loop through runs for a repo
-		} // End loop through repositories
-		log.Printf("Finished workflow run collection cycle.")
-	} // End ticker loop
+				if durationMs >= 0 && jobsBusySeconds >= 0 {
+					key := workflowKey{repoFullName, getFieldValue(repoFullName, *run, "workflow_name")}
+					overhead := aggregates.overheads[key]
+					overhead.seconds += math.Max(durationMs/1000-jobsBusySeconds, 0)
+					overhead.runs++
+					aggregates.overheads[key] = overhead
+				}
+			}
+		} // End loop through runs for a repo
+		budget.use(exportedRuns)
+	} // End loop through repositories
+	pacer.stop()
+	budget.logSkipped()
+	lastCycleReposGauge.Set(float64(processedRepos))
+	runSeries.finishCycle()
+	cycle := repoAggregates.finishCycle()
+	degraded := attemptedRepos > 0 && processedRepos == 0
+	setFetcherDegraded("getWorkflowRunsFromGithub", degraded)
+	if degraded {
+		return time.Since(cycleStart) - pacingWait // The aggregated metrics below would be exported empty
+	}
+	pruneRepoLastFetch(repositories)
+	pruneWorkflowUsageCache(getWorkflowsSnapshot())
+	cycle.exportInfo()
+	cycle.runsPerSHA.export()
+	cycle.latestRuns.export()
+	cycle.latestCompleted.export()
+	cycle.startDelays.export()
+	cycle.activeWorkflows.export()
+	cycle.reusableCallers.export()
+	workflowConcurrencyPendingGauge.Reset()
+	for key, count := range cycle.concurrencyPending {
+		workflowConcurrencyPendingGauge.WithLabelValues(key.repo, key.workflowName).Set(float64(count))
+	}
+	seenCutoff := getFetchWindowStart()
+	if widenedStart, ok := getRecentlyCompletedCreationStart(); ok && widenedStart.Before(seenCutoff) {
+		seenCutoff = widenedStart // Older runs completed recently are still listed, don't count them again
+	}
+	seenCancelledRuns.prune(seenCutoff)
+	seenCreatedRuns.prune(seenCutoff)
+	seenMissingStartRuns.prune(seenCutoff)
+	traces.send()
+	pruneEmittedTraceRuns(seenCutoff)
+
+	if config.Metrics.FetchWorkflowRunUsage && config.Metrics.FetchWorkflowJobs {
+		workflowRunOverheadGauge.Reset()
+		for key, overhead := range cycle.overheads {
+			workflowRunOverheadGauge.WithLabelValues(key.repo, key.workflowName).Set(overhead.seconds / float64(overhead.runs))
+		}
+	}
+	if config.Metrics.FetchWorkflowRunUsage {
+		workflowRunBillableSecondsGauge.Reset()
+		for key, seconds := range cycle.billableSeconds {
+			workflowRunBillableSecondsGauge.WithLabelValues(key.repo, key.workflowName, key.os).Set(seconds)
+		}
+	}
+
+	workflowRunWaitingGauge.Reset()
+	for key, seconds := range cycle.waitingSeconds {
+		workflowRunWaitingGauge.WithLabelValues(key.repo, key.workflowName, key.environment).Set(seconds)
+	}
+	if config.Metrics.FetchWorkflowJobs {
+		workflowJobRunnerTypeGauge.Reset()
+		for key, count := range cycle.jobRunnerTypes {
+			workflowJobRunnerTypeGauge.WithLabelValues(key.repo, key.workflowName, key.runnerType).Set(float64(count))
+		}
+		workflowRunsByOSGauge.Reset()
+		for key, count := range cycle.runOSes {
+			workflowRunsByOSGauge.WithLabelValues(key.repo, key.workflowName, key.os).Set(float64(count))
+		}
+		workflowRunFailureClassGauge.Reset()
+		for key, count := range cycle.failureClasses {
+			workflowRunFailureClassGauge.WithLabelValues(key.repo, key.workflowName, key.class).Set(float64(count))
+		}
+		jobsQueuedGauge.Reset()
+		for labels, count := range cycle.queuedJobs {
+			jobsQueuedGauge.WithLabelValues(labels).Set(float64(count))
+		}
+		hostedRunnerQueueDepthGauge.Reset()
+		for org, count := range cycle.hostedQueueDepths {
+			hostedRunnerQueueDepthGauge.WithLabelValues(org).Set(float64(count))
+		}
+	}
+	pruneWorkflowJobsCache(cycle.runIDs) // Also filled when resolving dispatch_input
+	if config.Metrics.FetchCheckRuns {
+		cycle.checkRuns.export()
+	}
+	if config.Metrics.ResolvePRFromCommit {
+		pruneCommitPullRequestCache(cycle.headSHAs)
+	}
+	log.Printf("Finished workflow run collection cycle.")
+	return time.Since(cycleStart) - pacingWait
 }
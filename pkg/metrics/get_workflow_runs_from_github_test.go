@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v72/github"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+)
+
+// setupUsageServer points the GitHub client to a server answering the run usage endpoint with status and body,
+// and returns the number of usage requests it received.
+func setupUsageServer(t *testing.T, status int, body string) *atomic.Int32 {
+	t.Helper()
+	var usageCalls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/org/repo/actions/runs/42/timing" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+		usageCalls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	previousClient := currentClient.Swap(client)
+	t.Cleanup(func() { currentClient.Store(previousClient) })
+	return &usageCalls
+}
+
+func TestGetWorkflowRunDurationMs(t *testing.T) {
+	previousMin, previousMaxHours := config.Metrics.UsageMinEstimatedDurationSeconds, config.Metrics.MaxPlausibleRunDurationHours
+	t.Cleanup(func() {
+		config.Metrics.UsageMinEstimatedDurationSeconds, config.Metrics.MaxPlausibleRunDurationHours = previousMin, previousMaxHours
+	})
+	config.Metrics.MaxPlausibleRunDurationHours = 0
+
+	const usageBody = `{"billable":{},"run_duration_ms":95000}`
+	startedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name           string
+		minEstimated   int64  // USAGE_MIN_ESTIMATED_DURATION_SECONDS
+		status         string // Run status
+		estimated      time.Duration
+		usageStatus    int
+		usageBody      string
+		wantMs         float64
+		wantUsage      bool
+		wantUsageCalls int32
+	}{
+		{"estimate below the minimum skips the API", 60, "completed", 30 * time.Second, http.StatusOK, usageBody, 30000, false, 0},
+		{"estimate above the minimum calls the API", 60, "completed", 120 * time.Second, http.StatusOK, usageBody, 95000, true, 1},
+		{"estimate equal to the minimum calls the API", 60, "completed", 60 * time.Second, http.StatusOK, usageBody, 95000, true, 1},
+		{"no minimum always calls the API", 0, "completed", 30 * time.Second, http.StatusOK, usageBody, 95000, true, 1},
+		{"run without estimate calls the API", 60, "in_progress", 30 * time.Second, http.StatusOK, usageBody, 95000, true, 1},
+		{"API error falls back to the estimate", 60, "completed", 120 * time.Second, http.StatusNotFound, `{"message":"Not Found"}`, 120000, false, 1},
+		{"usage without duration falls back to the estimate", 60, "completed", 120 * time.Second, http.StatusOK, `{"billable":{}}`, 120000, true, 1},
+		{"API error without estimate is unknown", 60, "in_progress", 30 * time.Second, http.StatusInternalServerError, `{}`, -1, false, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Metrics.UsageMinEstimatedDurationSeconds = tt.minEstimated
+			usageCalls := setupUsageServer(t, tt.usageStatus, tt.usageBody)
+			run := &github.WorkflowRun{
+				ID:           github.Ptr(int64(42)),
+				Status:       github.Ptr(tt.status),
+				CreatedAt:    &github.Timestamp{Time: startedAt},
+				RunStartedAt: &github.Timestamp{Time: startedAt},
+				UpdatedAt:    &github.Timestamp{Time: startedAt.Add(tt.estimated)},
+			}
+			if tt.status == "completed" {
+				run.Conclusion = github.Ptr("success")
+			}
+
+			gotMs, gotUsage := getWorkflowRunDurationMs("org", "repo", run)
+			if gotMs != tt.wantMs {
+				t.Errorf("getWorkflowRunDurationMs() duration = %v, want %v", gotMs, tt.wantMs)
+			}
+			if (gotUsage != nil) != tt.wantUsage {
+				t.Errorf("getWorkflowRunDurationMs() usage = %v, want usage: %v", gotUsage, tt.wantUsage)
+			}
+			if got := usageCalls.Load(); got != tt.wantUsageCalls {
+				t.Errorf("usage API calls = %d, want %d", got, tt.wantUsageCalls)
+			}
+		})
+	}
+}
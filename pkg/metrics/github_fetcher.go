@@ -1,37 +1,141 @@
 package metrics
 
 import (
-	"context"
+	"fmt"
 	"log"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/go-github/v72/github" // Ensure this is v72
+	"github.com/prometheus/client_golang/prometheus"
 
-	"github.com/spendesk/github-actions-exporter/pkg/config"
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
 )
 
 // NOTE: The global 'repositories' and 'workflows' are now declared in metrics.go
 // This file will UPDATE those global variables.
 
-func getAllReposForOrg(orga string) []string {
-	if client == nil { // client is the global from metrics.go
+var (
+	repoWorkflowCountGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_repo_workflow_count",
+			Help: "Number of workflows defined in a monitored repository (0 when it has none).",
+		},
+		[]string{"repo"},
+	)
+
+	// Repositories whose workflow definitions were fully fetched and turned out to be empty.
+	// Updated on each refresh; used to skip them when SKIP_REPOS_WITHOUT_WORKFLOWS is enabled.
+	reposWithoutWorkflows = make(map[string]bool)
+
+	// Key: "owner/repo", Value: the repository as returned by the API (default branch, ...).
+	// Filled from organization discovery, or fetched per repository for explicitly configured ones
+	// when a feature needs it (see needsRepoMetadata). Updated on each refresh, read through getRepoMetadata.
+	repoMetadata = make(map[string]*github.Repository)
+
+	// Guards 'workflows', 'reposWithoutWorkflows', 'repoSources' and 'workflowsLastAccess', which the workflow runs fetcher may
+	// fill on demand (see ensureWorkflowsForRepo) while periodicGithubFetcher refreshes them, and 'repoMetadata'.
+	// The other fetchers read them concurrently.
+	workflowsMu sync.RWMutex
+)
+
+// getPerPage returns the page size of API list calls (GITHUB_PER_PAGE, clamped to 1-100).
+func getPerPage() int {
+	if config.Github.PerPage < 1 {
+		return 1
+	}
+	if config.Github.PerPage > 100 {
+		return 100
+	}
+	return config.Github.PerPage
+}
+
+// getNextPage returns the next page of a list call, or 0 when there is none. The response may be nil
+// when a call fails, e.g. on transport errors, so pagination loops must not dereference it directly.
+func getNextPage(resp *github.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.NextPage
+}
+
+// needsRepoMetadata reports whether a feature relies on repoMetadata, so that it is fetched for explicitly configured repositories.
+func needsRepoMetadata() bool {
+	return config.Github.DefaultBranchOnly || config.Github.RepoVisibilityFilter != repoVisibilityAll || config.Github.ReportBlockedRepos ||
+		config.Metrics.FetchRepoBilling
+}
+
+// Values of REPO_VISIBILITY_FILTER.
+const (
+	repoVisibilityAll     = "all"
+	repoVisibilityPublic  = "public"
+	repoVisibilityPrivate = "private"
+)
+
+// validateRepoVisibilityFilter checks the value of REPO_VISIBILITY_FILTER.
+func validateRepoVisibilityFilter(filter string) error {
+	switch filter {
+	case repoVisibilityAll, repoVisibilityPublic, repoVisibilityPrivate:
+		return nil
+	}
+	return fmt.Errorf("unknown visibility %q, expected %s, %s or %s", filter, repoVisibilityAll, repoVisibilityPublic, repoVisibilityPrivate)
+}
+
+// isRepoVisibilityAllowed reports whether a repository matches REPO_VISIBILITY_FILTER.
+// Internal repositories (GitHub Enterprise) are private.
+func isRepoVisibilityAllowed(repo *github.Repository) bool {
+	switch config.Github.RepoVisibilityFilter {
+	case repoVisibilityPublic:
+		return !repo.GetPrivate()
+	case repoVisibilityPrivate:
+		return repo.GetPrivate()
+	}
+	return true
+}
+
+// repoWorkflowsFetch is the result of getAllWorkflowsForRepo for a repository.
+type repoWorkflowsFetch struct {
+	workflows map[int64]*github.Workflow
+	complete  bool
+}
+
+// getRepository fetches a single repository. It returns nil on error.
+func getRepository(owner string, repoName string) *github.Repository {
+	for {
+		repo, _, err := getClient().Repositories.Get(fetcherContext(fetcherDiscovery), owner, repoName)
+		recordAPIError(err)
+		if rlErr, ok := err.(*github.RateLimitError); ok {
+			log.Printf("Get repository ratelimited for %s/%s. Pausing until %s", owner, repoName, rlErr.Rate.Reset.Time.String())
+			sleepUntilRateLimitReset(rlErr.Rate.Reset.Time)
+			continue
+		} else if err != nil {
+			log.Printf("Get repository error for %s/%s: %v", owner, repoName, err)
+			return nil
+		}
+		return repo
+	}
+}
+
+func getAllReposForOrg(orga string) []*github.Repository {
+	if getClient() == nil { // client is the global from metrics.go
 		log.Printf("GitHub client not initialized in getAllReposForOrg for orga %s", orga)
 		return nil
 	}
-	var allRepos []string // Renamed to avoid confusion if there was a global with same name locally
+	var allRepos []*github.Repository // Renamed to avoid confusion if there was a global with same name locally
 
 	opt := &github.RepositoryListByOrgOptions{
 		ListOptions: github.ListOptions{
-			PerPage: 100, // Maximize items
+			PerPage: getPerPage(),
 		},
 	}
 	log.Printf("Fetching repositories for organization: %s", orga)
 	for {
-		reposPage, resp, err := client.Repositories.ListByOrg(context.Background(), orga, opt)
+		reposPage, resp, err := getClient().Repositories.ListByOrg(fetcherContext(fetcherDiscovery), orga, opt)
+		recordAPIError(err)
 		if rlErr, ok := err.(*github.RateLimitError); ok {
 			log.Printf("ListByOrg ratelimited for %s. Pausing until %s", orga, rlErr.Rate.Reset.Time.String())
-			time.Sleep(time.Until(rlErr.Rate.Reset.Time))
+			sleepUntilRateLimitReset(rlErr.Rate.Reset.Time)
 			continue
 		} else if err != nil {
 			log.Printf("ListByOrg error for organization %s: %s", orga, err.Error())
@@ -40,42 +144,44 @@ func getAllReposForOrg(orga string) []string {
 
 		for _, repo := range reposPage {
 			if repo != nil && repo.FullName != nil {
-				allRepos = append(allRepos, *repo.FullName)
+				allRepos = append(allRepos, repo)
 			}
 		}
 
-		if resp.NextPage == 0 {
+		recordPageFetched(resp)
+		if getNextPage(resp) == 0 {
 			break
 		}
-		opt.ListOptions.Page = resp.NextPage
+		opt.ListOptions.Page = getNextPage(resp)
 	}
 	log.Printf("Fetched %d repositories for organization: %s", len(allRepos), orga)
 	return allRepos
 }
 
 // getAllWorkflowsForRepo fetches workflow definitions for a single repository.
-// It now returns a map with pointers to github.Workflow.
-func getAllWorkflowsForRepo(owner string, repoName string) map[int64]*github.Workflow {
-	if client == nil { // client is the global from metrics.go
+// It now returns a map with pointers to github.Workflow, and whether all pages were fetched.
+func getAllWorkflowsForRepo(owner string, repoName string) (map[int64]*github.Workflow, bool) {
+	if getClient() == nil { // client is the global from metrics.go
 		log.Printf("GitHub client not initialized in getAllWorkflowsForRepo for %s/%s", owner, repoName)
-		return nil
+		return nil, false
 	}
 	res := make(map[int64]*github.Workflow)
 
 	opt := &github.ListOptions{
-		PerPage: 100, // Maximize items
+		PerPage: getPerPage(),
 	}
 
 	// log.Printf("Fetching workflow definitions for %s/%s", owner, repoName)
 	for {
-		workflowsPage, resp, err := client.Actions.ListWorkflows(context.Background(), owner, repoName, opt)
+		workflowsPage, resp, err := getClient().Actions.ListWorkflows(fetcherContext(fetcherDiscovery), owner, repoName, opt)
+		recordAPIError(err)
 		if rlErr, ok := err.(*github.RateLimitError); ok {
 			log.Printf("ListWorkflows ratelimited for %s/%s. Pausing until %s", owner, repoName, rlErr.Rate.Reset.Time.String())
-			time.Sleep(time.Until(rlErr.Rate.Reset.Time))
+			sleepUntilRateLimitReset(rlErr.Rate.Reset.Time)
 			continue
 		} else if err != nil {
 			log.Printf("ListWorkflows error for %s/%s: %s", owner, repoName, err.Error())
-			return res // Return what we have so far for this repo
+			return res, false // Return what we have so far for this repo
 		}
 
 		if workflowsPage != nil && workflowsPage.Workflows != nil {
@@ -86,19 +192,21 @@ func getAllWorkflowsForRepo(owner string, repoName string) map[int64]*github.Wor
 			}
 		}
 
-		if resp.NextPage == 0 {
+		recordPageFetched(resp)
+		if getNextPage(resp) == 0 {
 			break
 		}
-		opt.Page = resp.NextPage
+		opt.Page = getNextPage(resp)
 	}
 	// log.Printf("Fetched %d workflow definitions for %s/%s", len(res), owner, repoName)
-	return res
+	return res, true
 }
 
 // periodicGithubFetcher is intended to be run as a goroutine.
-// It updates the global 'repositories' and 'workflows' variables.
-func periodicGithubFetcher() {
-	if client == nil {
+// It updates the global 'repositories' and 'workflows' variables, and closes firstRefreshDone
+// once the first refresh is over (or was skipped because collection is paused).
+func periodicGithubFetcher(firstRefreshDone chan<- struct{}) {
+	if getClient() == nil {
 		log.Println("GitHub client not initialized at start of periodicGithubFetcher. Will retry.")
 	}
 
@@ -114,78 +222,245 @@ func periodicGithubFetcher() {
 		refreshIntervalSeconds = 60 // Minimum sensible interval
 	}
 	log.Printf("periodicGithubFetcher will refresh repositories and workflow definitions every %d seconds.", refreshIntervalSeconds)
+	expectFetcherCycles("periodicGithubFetcher")
 	ticker := time.NewTicker(time.Duration(refreshIntervalSeconds) * time.Second)
 	defer ticker.Stop()
 
 	for {
-		if client == nil { // Re-check client in loop in case it was initialized late
+		if getClient() == nil { // Re-check client in loop in case it was initialized late
 			log.Println("periodicGithubFetcher: GitHub client still not initialized. Sleeping.")
 			time.Sleep(60 * time.Second) // Wait before retrying client check
 			continue
 		}
 
-		log.Println("periodicGithubFetcher: Starting data refresh cycle...")
-		var reposToProcess []string
-		// Prioritize explicitly listed repositories
-		if config.Github.Repositories.Value() != nil && len(config.Github.Repositories.Value()) > 0 {
-			reposToProcess = config.Github.Repositories.Value()
-			log.Printf("periodicGithubFetcher: Using %d explicitly configured repositories.", len(reposToProcess))
-		} else if config.Github.Organizations.Value() != nil && len(config.Github.Organizations.Value()) > 0 {
-			log.Printf("periodicGithubFetcher: No explicit repositories configured, discovering from %d organization(s).", len(config.Github.Organizations.Value()))
-			for _, orga := range config.Github.Organizations.Value() {
-				if orga != "" { // Ensure org name is not empty
-					reposToProcess = append(reposToProcess, getAllReposForOrg(orga)...)
+		if !isCollectionPaused() {
+			cycleStart := time.Now()
+			refreshRepositoriesAndWorkflows()
+			observeCycleDuration("periodicGithubFetcher", time.Since(cycleStart), time.Duration(refreshIntervalSeconds)*time.Second)
+		}
+		if firstRefreshDone != nil {
+			close(firstRefreshDone)
+			firstRefreshDone = nil
+		}
+		<-ticker.C // Wait for the next tick
+	}
+}
+
+// refreshRepositoriesAndWorkflows runs a single refresh of the global 'repositories' and 'workflows' variables.
+func refreshRepositoriesAndWorkflows() {
+	log.Println("periodicGithubFetcher: Starting data refresh cycle...")
+	var reposToProcess []string
+	newRepoMetadata := make(map[string]*github.Repository)
+	newRepoSources := make(map[string]repoSource)
+	// Prioritize explicitly listed repositories
+	if config.Github.Repositories.Value() != nil && len(config.Github.Repositories.Value()) > 0 {
+		for _, repoFullName := range config.Github.Repositories.Value() {
+			reposToProcess = append(reposToProcess, getCanonicalRepoName(repoFullName))
+		}
+		log.Printf("periodicGithubFetcher: Using %d explicitly configured repositories.", len(reposToProcess))
+		if needsRepoMetadata() {
+			for i, repoFullName := range reposToProcess {
+				ownerAndRepo := strings.Split(repoFullName, "/")
+				if len(ownerAndRepo) != 2 {
+					continue
+				}
+				if repo := getRepository(ownerAndRepo[0], ownerAndRepo[1]); repo != nil {
+					if repo.GetFullName() != "" && repo.GetFullName() != repoFullName { // Followed a redirect
+						recordRepoRename(repoFullName, repo.GetFullName())
+						repoFullName = repo.GetFullName()
+						reposToProcess[i] = repoFullName
+					}
+					newRepoMetadata[repoFullName] = repo
+				} else if previous, ok := getRepoMetadata(repoFullName); ok {
+					newRepoMetadata[repoFullName] = previous // Keep the last known metadata on error
 				}
 			}
-			log.Printf("periodicGithubFetcher: Discovered %d repositories from organizations.", len(reposToProcess))
-		} else {
-			log.Println("periodicGithubFetcher: No repositories or organizations configured. Nothing to fetch.")
-			// Update globals to be empty to reflect this state
-			// Consider if lock is needed if other goroutines read these during assignment
-			// For simple assignment of the whole map/slice, it's often okay.
-			repositories = []string{}
-			workflows = make(map[string]map[int64]*github.Workflow)
-			<-ticker.C // Wait for next tick
-			continue
+			var visibleRepos []string
+			for _, repoFullName := range reposToProcess {
+				if repo, ok := newRepoMetadata[repoFullName]; ok && !isRepoVisibilityAllowed(repo) {
+					log.Printf("periodicGithubFetcher: Skipping %s, its visibility doesn't match REPO_VISIBILITY_FILTER=%s.", repoFullName, config.Github.RepoVisibilityFilter)
+					continue
+				}
+				visibleRepos = append(visibleRepos, repoFullName) // Kept when its visibility is unknown
+			}
+			reposToProcess = visibleRepos
 		}
-
-		// Deduplicate repositories list (if an org repo was also listed explicitly)
-		// This is a simple deduplication. For very large lists, more efficient methods exist.
-		uniqueReposMap := make(map[string]bool)
-		var uniqueReposList []string
 		for _, repoFullName := range reposToProcess {
-			if !uniqueReposMap[repoFullName] {
-				uniqueReposMap[repoFullName] = true
-				uniqueReposList = append(uniqueReposList, repoFullName)
-			}
+			newRepoSources[repoFullName] = repoSource{source: repoSourceRepositories}
 		}
-		// Update the global 'repositories' slice
-		// Consider mutex protection if other goroutines iterate over 'repositories' concurrently
-		// with this assignment. For now, direct assignment.
-		repositories = uniqueReposList
-		log.Printf("periodicGithubFetcher: Processing %d unique repositories.", len(repositories))
-
-		// Fetch workflows for the final list of repositories
-		newWorkflowsData := make(map[string]map[int64]*github.Workflow)
-		for _, repoFullName := range repositories { // Use the now updated global 'repositories'
-			ownerAndRepo := strings.Split(repoFullName, "/")
-			if len(ownerAndRepo) != 2 {
-				log.Printf("periodicGithubFetcher: Invalid repository format '%s'. Skipping workflow fetch.", repoFullName)
-				continue
+	} else if organizations := getOrganizationsToDiscover(); len(organizations) > 0 {
+		log.Printf("periodicGithubFetcher: No explicit repositories configured, discovering from %d organization(s).", len(organizations))
+		for _, orga := range organizations {
+			if orga != "" { // Ensure org name is not empty
+				for _, repo := range getAllReposForOrg(orga) {
+					if !isRepoVisibilityAllowed(repo) {
+						continue
+					}
+					reposToProcess = append(reposToProcess, repo.GetFullName())
+					newRepoMetadata[repo.GetFullName()] = repo
+					newRepoSources[repo.GetFullName()] = repoSource{source: repoSourceOrganization, organization: orga}
+				}
 			}
-			owner, repoName := ownerAndRepo[0], ownerAndRepo[1]
+		}
+		log.Printf("periodicGithubFetcher: Discovered %d repositories from organizations.", len(reposToProcess))
+	} else {
+		log.Println("periodicGithubFetcher: No repositories or organizations configured. Nothing to fetch.")
+		// Update globals to be empty to reflect this state
+		// Consider if lock is needed if other goroutines read these during assignment
+		// For simple assignment of the whole map/slice, it's often okay.
+		repositories = []string{}
+		workflowsMu.Lock()
+		workflows = make(map[string]map[int64]*github.Workflow)
+		reposWithoutWorkflows = make(map[string]bool)
+		repoSources = make(map[string]repoSource)
+		repoMetadata = make(map[string]*github.Repository)
+		workflowsMu.Unlock()
+		repoWorkflowCountGauge.Reset()
+		repoActionsBlockedGauge.Reset()
+		return
+	}
 
-			workflowsForRepo := getAllWorkflowsForRepo(owner, repoName)
-			if len(workflowsForRepo) > 0 { // Only add if there are workflows
-				newWorkflowsData[repoFullName] = workflowsForRepo
-				// log.Printf("periodicGithubFetcher: Fetched %d workflows for %s", len(workflowsForRepo), repoFullName)
-			}
+	// Deduplicate repositories list (if an org repo was also listed explicitly)
+	// This is a simple deduplication. For very large lists, more efficient methods exist.
+	uniqueReposMap := make(map[string]bool)
+	var uniqueReposList []string
+	for _, repoFullName := range reposToProcess {
+		if !uniqueReposMap[repoFullName] {
+			uniqueReposMap[repoFullName] = true
+			uniqueReposList = append(uniqueReposList, repoFullName)
 		}
+	}
+	// Update the global 'repositories' slice
+	// Consider mutex protection if other goroutines iterate over 'repositories' concurrently
+	// with this assignment. For now, direct assignment.
+	repositories = uniqueReposList
+	workflowsMu.Lock()
+	repoMetadata = newRepoMetadata
+	workflowsMu.Unlock()
+	log.Printf("periodicGithubFetcher: Processing %d unique repositories.", len(repositories))
+	if config.Github.FetchStrategy == fetchStrategySearch {
+		refreshSearchedWorkflowFiles(repositories)
+	}
+
+	// Fetch workflows for the final list of repositories, FETCH_CONCURRENCY repositories at a time.
+	// A repository whose fetch fails is left out without affecting the others.
+	var reposToFetch []string
+	for _, repoFullName := range repositories { // Use the now updated global 'repositories'
+		if len(strings.Split(repoFullName, "/")) != 2 {
+			log.Printf("periodicGithubFetcher: Invalid repository format '%s'. Skipping workflow fetch.", repoFullName)
+			continue
+		}
+		if config.Github.FetchStrategy == fetchStrategySearch && len(getSearchedWorkflowFiles(repoFullName)) == 0 {
+			continue // Its runs aren't fetched, see refreshSearchedWorkflowFiles
+		}
+		reposToFetch = append(reposToFetch, repoFullName)
+	}
+	fetchedWorkflows := fetchConcurrently(reposToFetch, func(repoFullName string) repoWorkflowsFetch {
+		owner, repoName, _ := strings.Cut(repoFullName, "/")
+		workflowsForRepo, complete := getAllWorkflowsForRepo(owner, repoName)
+		return repoWorkflowsFetch{workflowsForRepo, complete}
+	})
 
-		// Atomically update the global 'workflows' map (or use a mutex)
-		workflows = newWorkflowsData
-		log.Printf("periodicGithubFetcher: Workflow definitions cache updated. Repos with workflows: %d. Total unique repos monitored: %d", len(workflows), len(repositories))
+	newWorkflowsData := make(map[string]map[int64]*github.Workflow)
+	newReposWithoutWorkflows := make(map[string]bool)
+	repoWorkflowCountGauge.Reset()
+	for _, repoFullName := range reposToFetch {
+		workflowsForRepo, complete := fetchedWorkflows[repoFullName].workflows, fetchedWorkflows[repoFullName].complete
+		if len(workflowsForRepo) > 0 { // Only add if there are workflows
+			newWorkflowsData[repoFullName] = workflowsForRepo
+			// log.Printf("periodicGithubFetcher: Fetched %d workflows for %s", len(workflowsForRepo), repoFullName)
+		} else if complete {
+			newReposWithoutWorkflows[repoFullName] = true
+		}
+		if complete {
+			repoWorkflowCountGauge.WithLabelValues(repoFullName).Set(float64(len(workflowsForRepo)))
+		}
+	}
 
-		<-ticker.C // Wait for the next tick
+	if config.Github.ReportBlockedRepos {
+		updateRepoActionsBlocked(repositories, newRepoMetadata, newWorkflowsData)
 	}
-}
\ No newline at end of file
+
+	workflowsMu.Lock()
+	workflows = newWorkflowsData
+	reposWithoutWorkflows = newReposWithoutWorkflows
+	repoSources = newRepoSources
+	for repoFullName := range workflowsLastAccess {
+		if _, ok := workflows[repoFullName]; !ok {
+			delete(workflowsLastAccess, repoFullName)
+		}
+	}
+	evictCachedWorkflows("")
+	workflowsMu.Unlock()
+	if len(newReposWithoutWorkflows) > 0 {
+		log.Printf("periodicGithubFetcher: %d repositories have no workflows configured.", len(newReposWithoutWorkflows))
+	}
+	log.Printf("periodicGithubFetcher: Workflow definitions cache updated. Repos with workflows: %d. Total unique repos monitored: %d", len(newWorkflowsData), len(repositories))
+}
+
+// getCachedWorkflow returns the cached definition of a workflow, or nil when it isn't known.
+func getCachedWorkflow(repoFullName string, workflowID int64) *github.Workflow {
+	workflowsMu.RLock()
+	defer workflowsMu.RUnlock()
+	return workflows[repoFullName][workflowID]
+}
+
+// getRepoMetadata returns the repository as returned by the API, when known.
+func getRepoMetadata(repoFullName string) (*github.Repository, bool) {
+	workflowsMu.RLock()
+	defer workflowsMu.RUnlock()
+	repo, ok := repoMetadata[repoFullName]
+	return repo, ok
+}
+
+// getWorkflowsSnapshot returns a shallow copy of the workflow definitions cache, safe to iterate over.
+func getWorkflowsSnapshot() map[string]map[int64]*github.Workflow {
+	workflowsMu.RLock()
+	defer workflowsMu.RUnlock()
+	snapshot := make(map[string]map[int64]*github.Workflow, len(workflows))
+	for repoFullName, repoWorkflows := range workflows {
+		snapshot[repoFullName] = repoWorkflows
+	}
+	return snapshot
+}
+
+// hasNoWorkflows reports whether a repository's workflow definitions were fully fetched and turned out to be empty.
+func hasNoWorkflows(repoFullName string) bool {
+	workflowsMu.RLock()
+	defer workflowsMu.RUnlock()
+	return reposWithoutWorkflows[repoFullName]
+}
+
+// ensureWorkflowsForRepo makes sure the workflow definitions of a repository are cached before its runs
+// are exported, fetching them on demand when periodicGithubFetcher hasn't done so yet (e.g. right after
+// startup, or when its last refresh failed). It returns false when they are still unknown, in which case
+// the repository should be deferred to the next cycle rather than exported with unknown_workflow_name.
+func ensureWorkflowsForRepo(owner, repoName string) bool {
+	repoFullName := owner + "/" + repoName
+	workflowsMu.Lock()
+	_, cached := workflows[repoFullName]
+	noWorkflows := reposWithoutWorkflows[repoFullName]
+	if cached {
+		workflowsLastAccess[repoFullName] = time.Now()
+	}
+	workflowsMu.Unlock()
+	if cached || noWorkflows {
+		return true
+	}
+
+	workflowsForRepo, complete := getAllWorkflowsForRepo(owner, repoName)
+	if !complete {
+		return false
+	}
+	workflowsMu.Lock()
+	if len(workflowsForRepo) > 0 {
+		workflows[repoFullName] = workflowsForRepo
+		workflowsLastAccess[repoFullName] = time.Now()
+		evictCachedWorkflows(repoFullName)
+	} else {
+		reposWithoutWorkflows[repoFullName] = true
+	}
+	workflowsMu.Unlock()
+	log.Printf("Fetched %d workflow definitions of %s on demand.", len(workflowsForRepo), repoFullName)
+	return true
+}
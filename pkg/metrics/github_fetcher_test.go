@@ -0,0 +1,30 @@
+package metrics
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v72/github"
+)
+
+func TestGetNextPage(t *testing.T) {
+	tests := []struct {
+		name string
+		resp *github.Response
+		want int
+	}{
+		{"nil response", nil, 0},
+		{"nil http response", &github.Response{}, 0},
+		{"nil http response with a next page", &github.Response{NextPage: 3}, 3},
+		{"last page", &github.Response{Response: &http.Response{StatusCode: http.StatusOK}}, 0},
+		{"next page", &github.Response{Response: &http.Response{StatusCode: http.StatusOK}, NextPage: 2}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getNextPage(tt.resp); got != tt.want {
+				t.Errorf("getNextPage() = %d, want %d", got, tt.want)
+			}
+			recordPageFetched(tt.resp) // Called with the same response by the pagination loops, must not panic either
+		})
+	}
+}
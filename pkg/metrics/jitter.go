@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+)
+
+// Random source of the fetcher jitters, seeded with JITTER_SEED when set so delays are reproducible.
+// Fetchers run concurrently, hence the mutex.
+var (
+	jitterMu   sync.Mutex
+	jitterRand *rand.Rand
+)
+
+// initJitter seeds the random source of the jitters.
+func initJitter() {
+	seed := config.Github.JitterSeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	jitterMu.Lock()
+	jitterRand = rand.New(rand.NewSource(seed))
+	jitterMu.Unlock()
+}
+
+// randomDuration returns a random duration in [0, max).
+func randomDuration(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	jitterMu.Lock()
+	defer jitterMu.Unlock()
+	if jitterRand == nil {
+		jitterRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return time.Duration(jitterRand.Int63n(int64(max)))
+}
+
+// sleepStartupJitter delays the first tick of a fetcher by up to STARTUP_JITTER_SECONDS, so replicas
+// started together don't query GitHub in lockstep.
+func sleepStartupJitter(fetcher string) {
+	delay := randomDuration(time.Duration(config.Github.StartupJitterSeconds) * time.Second)
+	if delay > 0 {
+		log.Printf("%s: Delaying start by %v (STARTUP_JITTER_SECONDS).", fetcher, delay.Round(time.Millisecond))
+		time.Sleep(delay)
+	}
+}
+
+// sleepTickJitter delays a collection cycle by up to TICK_JITTER_SECONDS.
+func sleepTickJitter() {
+	time.Sleep(randomDuration(time.Duration(config.Github.TickJitterSeconds) * time.Second))
+}
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Key: field name, Value: glob patterns of the values kept as-is, parsed from LABEL_VALUE_ALLOWLIST.
+// Values of these fields matching none of the patterns are replaced with LABEL_VALUE_OTHER.
+var labelValueAllowlist = make(map[string][]string)
+
+// labelValueOther is the value of the fields whose value is not allowlisted.
+var labelValueOther = "other"
+
+// parseLabelValueAllowlist parses "field=pattern" entries. Since entries are comma-separated, an entry without
+// "=" adds a pattern to the field of the previous entry: head_branch=main,develop,release/* keeps three patterns.
+func parseLabelValueAllowlist(entries []string) (map[string][]string, error) {
+	knownFields := make(map[string]bool)
+	fieldNames := append(append([]string{}, directFieldNames...), derivedFieldNames...)
+	for _, fieldName := range append(fieldNames, getPathDerivedLabelNames(pathDerivedLabelPattern)...) {
+		knownFields[fieldName] = true
+	}
+
+	allowlist := make(map[string][]string)
+	fieldName := ""
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		pattern := entry
+		if name, value, found := strings.Cut(entry, "="); found {
+			fieldName, pattern = strings.TrimSpace(name), strings.TrimSpace(value)
+			if !knownFields[fieldName] {
+				return nil, fmt.Errorf("unknown field %q", fieldName)
+			}
+			if _, duplicated := allowlist[fieldName]; duplicated {
+				return nil, fmt.Errorf("field %q is set more than once", fieldName)
+			}
+		} else if fieldName == "" {
+			return nil, fmt.Errorf("%q is not formatted as <field>=<pattern>", entry)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q for field %s: %w", pattern, fieldName, err)
+		}
+		allowlist[fieldName] = append(allowlist[fieldName], pattern)
+	}
+	return allowlist, nil
+}
+
+// allowLabelValue returns the value of a field, or labelValueOther when the field has an allowlist
+// that none of its patterns match. As in path.Match, * doesn't match "/".
+func allowLabelValue(fieldName string, value string) string {
+	patterns, ok := labelValueAllowlist[fieldName]
+	if !ok {
+		return value
+	}
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, value); matched { // Patterns are validated when parsed
+			return value
+		}
+	}
+	return labelValueOther
+}
@@ -5,11 +5,16 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"os"
 	// "net/url" // <<< REMOVE THIS LINE if getEnterpriseApiUrl helper is not used
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/spendesk/github-actions-exporter/pkg/config"
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+	"github.com/markomanboi/github-actions-exporter/pkg/version"
 
 	"github.com/bradleyfalzon/ghinstallation/v2"
 	"github.com/die-net/lrucache"
@@ -20,17 +25,55 @@ import (
 )
 
 var (
-	client *github.Client // Global GitHub client instance
+	// Global GitHub client instance, swapped by noteAuthError when the credentials rotate. Read it with getClient.
+	currentClient atomic.Pointer[github.Client]
+
+	buildInfoGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_exporter_build_info",
+			Help: "Build information of the running exporter (always 1).",
+		},
+		[]string{"version", "commit", "go_version"},
+	)
+
+	configInfoGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_exporter_config_info",
+			Help: "GitHub API the exporter is pointed at and how it authenticates (always 1).",
+		},
+		[]string{"api_url", "auth_mode"},
+	)
+
+	// How the last client built by NewClient authenticates: "token", "app" or "anonymous". Guarded by clientAuthMu.
+	authMode string
+
+	clientAuthMu sync.RWMutex
 
 	// Workflow Run Metrics
-	workflowRunStatusGauge   *prometheus.GaugeVec
-	workflowRunDurationGauge *prometheus.GaugeVec
+	workflowRunStatusGauge          *prometheus.GaugeVec
+	workflowRunDurationGauge        *prometheus.GaugeVec // Deprecated github_workflow_run_duration_ms, kept for existing dashboards
+	workflowRunDurationSecondsGauge *prometheus.GaugeVec
+
+	workflowRunBillableSecondsGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_workflow_run_billable_seconds",
+			Help: "Billable time of the runs of a workflow in the fetch window, by runner environment, from the per-run usage API.",
+		},
+		[]string{"repo", "workflow_name", "os"},
+	)
+
+	// Label names of the workflow run metrics, parsed from config.WorkflowFields
+	workflowRunFieldNames []string
 
 	// Global cache for workflow definitions (ID to Name mapping)
 	// Key: "owner/repo", Value: map[workflow_id]*github.Workflow
 	// This is DECLARED HERE and UPDATED by functions in github_fetcher.go
 	workflows map[string]map[int64]*github.Workflow = make(map[string]map[int64]*github.Workflow)
 
+	// Registerer of all the exporter metrics, prefixing their names with METRIC_NAMESPACE and
+	// adding STATIC_LABELS when set.
+	registerer prometheus.Registerer = prometheus.DefaultRegisterer
+
 	// Slice of repositories to monitor, populated from config or discovered.
 	// This is DECLARED HERE and UPDATED by functions in github_fetcher.go
 	repositories []string
@@ -48,54 +91,265 @@ func InitMetrics() {
 	// 'InitMetrics' will set up gauges and start the goroutines.
 
 	// --- Initialize Prometheus Gauges ---
-	if config.WorkflowFields == "" {
-		log.Fatalln("Error: Configuration 'WorkflowFields' (env: EXPORT_FIELDS_WORKFLOW_RUN) is empty. Cannot initialize workflow_run_status metric.")
+	staticLabels, staticLabelsErr := parseStaticLabels(config.StaticLabels.Value())
+	if staticLabelsErr != nil {
+		log.Fatalf("Error: Invalid configuration 'static_labels' (env: STATIC_LABELS): %v", staticLabelsErr)
+	}
+	if len(staticLabels) > 0 {
+		registerer = prometheus.WrapRegistererWith(staticLabels, registerer)
 	}
-	workflowRunLabelNames := strings.Split(config.WorkflowFields, ",")
+	if config.MetricNamespace != "" {
+		registerer = prometheus.WrapRegistererWithPrefix(config.MetricNamespace+"_", registerer)
+	}
+	registerer.MustRegister(buildInfoGauge)
+	buildInfoGauge.WithLabelValues(version.Version, version.Commit, version.GoVersion()).Set(1)
 
-	workflowRunStatusGauge = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Name: "github_workflow_run_status",
-			Help: "Status of GitHub Actions workflow runs. Fetches runs created within the 'fetch_max_workflow_creation_age_hours'. " +
-				"Labels are defined by 'export_fields_workflow_run' config.",
-		},
-		workflowRunLabelNames,
-	)
-	prometheus.MustRegister(workflowRunStatusGauge)
+	pathPattern, pathPatternErr := compilePathDerivedLabelPattern(config.Metrics.PathDerivedLabelRegex)
+	if pathPatternErr != nil {
+		log.Fatalf("Error: Invalid configuration 'path_derived_label_regex' (env: PATH_DERIVED_LABEL_REGEX): %v", pathPatternErr)
+	}
+	pathDerivedLabelPattern = pathPattern
+	for _, labelName := range getPathDerivedLabelNames(pathPattern) {
+		if _, clash := staticLabels[labelName]; clash {
+			log.Fatalf("Error: Invalid configuration 'static_labels' (env: STATIC_LABELS): label %q is also a capture group of 'path_derived_label_regex'", labelName)
+		}
+	}
+
+	workflowRunLabelNames, fieldsErr := parseWorkflowFields(config.WorkflowFields)
+	if fieldsErr != nil {
+		log.Fatalf("Error: Invalid configuration 'export_fields' (env: EXPORT_FIELDS_WORKFLOW_RUN): %v", fieldsErr)
+	}
+	workflowRunFieldNames = workflowRunLabelNames
+
+	if err := validateDispatchInputConfig(); err != nil {
+		log.Fatalf("Error: Invalid configuration 'export_fields' (env: EXPORT_FIELDS_WORKFLOW_RUN): %v", err)
+	}
+	inputPattern, inputPatternErr := compileDispatchInputPattern(config.Metrics.DispatchInputKey)
+	if inputPatternErr != nil {
+		log.Fatalf("Error: Invalid configuration 'dispatch_input_key' (env: DISPATCH_INPUT_KEY): %v", inputPatternErr)
+	}
+	dispatchInputPattern = inputPattern
+
+	rates, ratesErr := parseSampleRateOverrides(config.Metrics.SampleRateOverrides.Value())
+	if ratesErr != nil {
+		log.Fatalf("Error: Invalid configuration 'sample_rate_overrides' (env: SAMPLE_RATE_OVERRIDES): %v", ratesErr)
+	}
+	sampleRates = rates
+
+	allowlist, allowlistErr := parseLabelValueAllowlist(config.Metrics.LabelValueAllowlist.Value())
+	if allowlistErr != nil {
+		log.Fatalf("Error: Invalid configuration 'label_value_allowlist' (env: LABEL_VALUE_ALLOWLIST): %v", allowlistErr)
+	}
+	labelValueAllowlist = allowlist
+	labelValueOther = config.Metrics.LabelValueOther
+
+	statusValues, statusValuesErr := parseRunnerStatusValueMap(config.Metrics.RunnerStatusValueMap)
+	if statusValuesErr != nil {
+		log.Fatalf("Error: Invalid configuration 'runner_status_value_map' (env: RUNNER_STATUS_VALUE_MAP): %v", statusValuesErr)
+	}
+	runnerStatusValues = statusValues
+
+	conclusions, conclusionsErr := parseFailureConclusions(config.Metrics.FailureConclusions.Value())
+	if conclusionsErr != nil {
+		log.Fatalf("Error: Invalid configuration 'failure_conclusions' (env: FAILURE_CONCLUSIONS): %v", conclusionsErr)
+	}
+	failureConclusions = conclusions
+
+	buckets, bucketsErr := parseConclusionBuckets(config.Metrics.ConclusionBuckets)
+	if bucketsErr != nil {
+		log.Fatalf("Error: Invalid configuration 'conclusion_buckets' (env: CONCLUSION_BUCKETS): %v", bucketsErr)
+	}
+	conclusionBuckets = buckets
+
+	if err := validateCommitTitleMode(config.Metrics.CommitTitleMode); err != nil {
+		log.Fatalf("Error: Invalid configuration 'commit_title_mode' (env: COMMIT_TITLE_MODE): %v", err)
+	}
+
+	if err := validateRepoScheduling(config.Github.RepoScheduling); err != nil {
+		log.Fatalf("Error: Invalid configuration 'repo_scheduling' (env: REPO_SCHEDULING): %v", err)
+	}
+
+	if err := validateFetchStrategy(); err != nil {
+		log.Fatalf("Error: Invalid configuration 'fetch_strategy' (env: FETCH_STRATEGY): %v", err)
+	}
+
+	if err := validateRepoVisibilityFilter(config.Github.RepoVisibilityFilter); err != nil {
+		log.Fatalf("Error: Invalid configuration 'repo_visibility_filter' (env: REPO_VISIBILITY_FILTER): %v", err)
+	}
+
+	workflowRunStatusGauge, workflowRunDurationGauge, workflowRunDurationSecondsGauge = newWorkflowRunGauges(workflowRunLabelNames)
+	workflowRunGauges.setGauges(workflowRunStatusGauge, workflowRunDurationGauge, workflowRunDurationSecondsGauge)
+	registerer.MustRegister(workflowRunGauges)
 
 	if config.Metrics.FetchWorkflowRunUsage {
-		workflowRunDurationGauge = prometheus.NewGaugeVec(
-			prometheus.GaugeOpts{
-				Name: "github_workflow_run_duration_ms",
-				Help: "Duration of GitHub Actions workflow runs in milliseconds. Subject to the same fetching rules as run status.",
-			},
-			workflowRunLabelNames, // Assuming duration uses the same labels for simplicity
-		)
-		prometheus.MustRegister(workflowRunDurationGauge)
+		registerer.MustRegister(workflowRunBillableSecondsGauge)
+	}
+
+	registerer.MustRegister(workflowRunWaitingGauge)
+	registerer.MustRegister(workflowRunReferencedGauge)
+	registerer.MustRegister(reusableWorkflowUsageGauge)
+	registerer.MustRegister(repoWorkflowCountGauge)
+	if config.Github.ReportBlockedRepos {
+		registerer.MustRegister(repoActionsBlockedGauge)
+	}
+	registerer.MustRegister(workflowCacheEvictionsCounter)
+	registerer.MustRegister(workflowRunsPerSHAGauge)
+	registerer.MustRegister(workflowConcurrencyCancellationsCounter)
+	registerer.MustRegister(workflowRunsCreatedCounter)
+	registerer.MustRegister(workflowRunsMissingStartTimeCounter)
+	registerer.MustRegister(scheduledRunLastTimestampGauge)
+	registerer.MustRegister(workflowConcurrencyPendingGauge)
+	registerer.MustRegister(workflowLatestRunStatusGauge)
+	registerer.MustRegister(workflowRunFailedGauge)
+	registerer.MustRegister(activeWorkflowsGauge)
+	registerer.MustRegister(workflowRunStartDelayGauge)
+	registerer.MustRegister(apiRequestDurationHistogram)
+	registerer.MustRegister(apiLastFreshResponseGauge)
+	registerer.MustRegister(apiRateLimitRemainingGauge)
+	registerer.MustRegister(apiClockSkewGauge)
+	registerer.MustRegister(fetcherCacheHitRatioGauge)
+	registerer.MustRegister(apiPagesFetchedCounter)
+	registerer.MustRegister(apiErrorsCounter)
+	registerer.MustRegister(repoFetchPacingGauge)
+	registerer.MustRegister(repoLastFetchGauge)
+	registerer.MustRegister(runsSkippedBudgetCounter)
+	registerer.MustRegister(clientReloadsCounter)
+	registerer.MustRegister(workflowRunRerunInfoGauge)
+	registerer.MustRegister(workflowRunLinksGauge)
+
+	if config.Metrics.FetchWorkflowJobs {
+		registerer.MustRegister(workflowJobRunnerTypeGauge)
+		registerer.MustRegister(jobsQueuedGauge)
+		registerer.MustRegister(workflowRunsByOSGauge)
+		registerer.MustRegister(workflowRunFailureClassGauge)
+		registerer.MustRegister(hostedRunnerQueueDepthGauge)
+	}
+
+	if config.Metrics.FetchWorkflowRunUsage && config.Metrics.FetchWorkflowJobs {
+		registerer.MustRegister(workflowRunOverheadGauge)
+	}
+
+	if config.Metrics.FetchDeployments {
+		registerer.MustRegister(deploymentSuccessCounter)
+	}
+
+	if config.Metrics.FetchCheckRuns {
+		registerer.MustRegister(checkRunStatusGauge)
+	}
+
+	if config.Metrics.FetchCacheUsage {
+		registerer.MustRegister(actionsCacheSizeGauge)
+		registerer.MustRegister(actionsCacheCountGauge)
+	}
+
+	if config.Metrics.FetchRepoBilling {
+		registerer.MustRegister(repoActionsUsageGauge)
+		registerer.MustRegister(billingAPICallsSavedCounter)
+	}
+
+	if config.Metrics.FetchOrgSecretsCount {
+		registerer.MustRegister(orgActionsSecretsCountGauge)
+		registerer.MustRegister(orgActionsVariablesCountGauge)
+	}
+
+	if config.Metrics.FetchOrgActionsPermissions {
+		registerer.MustRegister(orgActionsPermissionsGauge)
+		registerer.MustRegister(orgActionsSelectedActionsGauge)
+	}
+
+	if config.Metrics.FetchScaleSets {
+		registerer.MustRegister(runnerScaleSetReplicasGauge)
+		registerer.MustRegister(runnerScaleSetBusyReplicasGauge)
+	}
+
+	if config.Metrics.FetchRunners {
+		registerer.MustRegister(runnersGauge)
+		registerer.MustRegister(runnersOrganizationGauge)
+		registerer.MustRegister(runnersEnterpriseGauge)
+		registerer.MustRegister(runnerStatusTransitionsCounter)
+		registerer.MustRegister(runnerLastTransitionGauge)
+		registerer.MustRegister(runnerUtilizationGauge)
+		registerer.MustRegister(runnersOfflineGauge)
 	}
 
 	// TODO: Register other metrics if you use them
 
 	// --- Initialize GitHub Client ---
-	var clientErr error
-	client, clientErr = NewClient() // 'client' is our global client
+	newClient, clientErr := NewClient()
 	if clientErr != nil {
 		log.Fatalf("Error: GitHub client creation failed: %v", clientErr)
 	}
+	currentClient.Store(newClient)
+	registerer.MustRegister(apiRateLimitGauge)
+	detectRateLimits()
+	registerer.MustRegister(configInfoGauge)
+	configInfoGauge.WithLabelValues(config.Github.APIURL, getAuthMode()).Set(1)
+	registerer.MustRegister(exporterPausedGauge)
+	registerer.MustRegister(fetcherGoroutinesGauge)
+	registerer.MustRegister(lastCycleReposGauge)
+	registerer.MustRegister(exporterDegradedGauge)
+	registerer.MustRegister(scrapeOverrunGauge)
+	registerer.MustRegister(scrapeOverrunSecondsGauge)
+
+	if config.RunOnce {
+		return // Collection is driven by CollectOnce
+	}
 
 	// --- Start Goroutines for Metric Collection ---
+	initJitter()
 	// Start fetcher for repository list and workflow definitions (ID -> Name mapping)
 	// This will also perform an initial fetch.
-	go periodicGithubFetcher() // This function is now in github_fetcher.go
+	firstRefreshDone := make(chan struct{})
+	startFetcher(func() { periodicGithubFetcher(firstRefreshDone) }) // This function is now in github_fetcher.go
 
-	// Optional: Wait for the first fetch of repositories and workflow definitions.
-	// This helps ensure 'repositories' and 'workflows' have some data before 'getWorkflowRunsFromGithub' heavily relies on them.
-	log.Println("Waiting briefly for initial repository and workflow definition fetch...")
-	time.Sleep(10 * time.Second) // Adjust as needed, or implement a channel/waitgroup for true sync.
+	// Wait for the first fetch of repositories and workflow definitions, so that 'getWorkflowRunsFromGithub'
+	// resolves workflow names from its first cycle. With PRIME_CACHE_ON_STARTUP, /metrics is only served once
+	// it's over (InitMetrics runs before the server starts), otherwise the wait is short and best effort.
+	primeTimeout := 10 * time.Second
+	if config.Github.PrimeCacheOnStartup {
+		primeTimeout = time.Duration(config.Github.PrimeCacheTimeoutSeconds) * time.Second
+	}
+	log.Printf("Waiting up to %s for the initial repository and workflow definition fetch...", primeTimeout)
+	select {
+	case <-firstRefreshDone:
+		log.Println("Initial repository and workflow definition fetch done.")
+	case <-time.After(primeTimeout):
+		log.Printf("Initial repository and workflow definition fetch not done after %s, starting the workflow run fetcher anyway. Runs of repositories without cached workflows are deferred.", primeTimeout)
+	}
 
 	// Start fetcher for workflow runs (the main data we're interested in)
 	// getWorkflowRunsFromGithub will use the global 'repositories' list.
-	go getWorkflowRunsFromGithub() // This function is in get_workflow_runs_from_github.go
+	startFetcher(getWorkflowRunsFromGithub) // This function is in get_workflow_runs_from_github.go
+
+	if config.Metrics.FetchDeployments {
+		startFetcher(getDeploymentsFromGithub)
+	}
+
+	if config.Metrics.FetchCacheUsage {
+		startFetcher(getActionsCacheUsageFromGithub)
+	}
+
+	if config.Metrics.FetchRunners {
+		startFetcher(getRunnersFromGithub)
+		startFetcher(getRunnersOrganizationFromGithub)
+		startFetcher(getRunnersEnterpriseFromGithub)
+	}
+
+	if config.Metrics.FetchRepoBilling {
+		startFetcher(getRepoBillingFromGithub)
+	}
+
+	if config.Metrics.FetchOrgSecretsCount {
+		startFetcher(getOrgSecretsCountFromGithub)
+	}
+
+	if config.Metrics.FetchOrgActionsPermissions {
+		startFetcher(getOrgActionsPermissionsFromGithub)
+	}
+
+	if config.Metrics.FetchScaleSets {
+		startFetcher(getRunnerScaleSetsFromGithub)
+	}
 
 	// TODO: Start other metric gathering goroutines if they exist (e.g., for billing, runners)
 	// Example: if workflowBillGauge != nil { go getBillableFromGithub() }
@@ -104,6 +358,55 @@ func InitMetrics() {
 	log.Println("GitHub Actions Exporter initialized and metrics collection started.")
 }
 
+// CollectOnce runs a single collection cycle of every enabled fetcher, waits for all of them
+// to complete and exports the result to the Pushgateway and textfile if configured. Used by RUN_ONCE.
+func CollectOnce() {
+	log.Println("Running a single collection cycle (RUN_ONCE).")
+	refreshRepositoriesAndWorkflows()
+
+	var wg sync.WaitGroup
+	collect := func(cycle func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cycle()
+		}()
+	}
+
+	if len(repositories) > 0 {
+		runSeries := newRepoSeriesTracker(workflowRunStatusGauge, workflowRunDurationGauge, workflowRunDurationSecondsGauge)
+		collect(func() { collectWorkflowRuns(0, runSeries, newRepoAggregateTracker()) }) // No interval to spread fetches over
+	}
+	if config.Metrics.FetchDeployments {
+		collect(collectDeployments)
+	}
+	if config.Metrics.FetchCacheUsage {
+		collect(collectActionsCacheUsage)
+	}
+	if config.Metrics.FetchRepoBilling {
+		collect(collectRepoBilling)
+	}
+	if config.Metrics.FetchOrgSecretsCount {
+		collect(collectOrgSecretsCount)
+	}
+	if config.Metrics.FetchOrgActionsPermissions {
+		collect(collectOrgActionsPermissions)
+	}
+	if config.Metrics.FetchScaleSets {
+		collect(collectRunnerScaleSets)
+	}
+	if config.Metrics.FetchRunners {
+		collect(collectRepoRunners)
+		collect(collectOrganizationRunners)
+		collect(collectEnterpriseRunners)
+	}
+	wg.Wait()
+
+	pushToPushgateway()
+	writeTextfile()
+	log.Println("Single collection cycle finished.")
+}
+
 
 // NewClient creates and configures a new GitHub API client. (Code from previous response, ensure it's up-to-date)
 func NewClient() (*github.Client, error) {
@@ -113,29 +416,62 @@ func NewClient() (*github.Client, error) {
 		cacheSizeBytes = 10 * 1024 * 1024
 	}
 	lruCache := lrucache.New(cacheSizeBytes, 0)
+
+	token := config.Github.Token
+	if config.Github.TokenFile != "" {
+		tokenBytes, err := os.ReadFile(config.Github.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading GitHub token file failed: %w", err)
+		}
+		token = strings.TrimSpace(string(tokenBytes))
+	}
+
+	appKeyFiles := getAppPrivateKeyFiles()
+	var mode string
+	var installationID string // Labels the rate-limit budget of the installation
+	if token == "" && config.Github.AppID != 0 && config.Github.AppInstallationID != 0 && len(appKeyFiles) > 0 {
+		installationID = strconv.FormatInt(config.Github.AppInstallationID, 10)
+	}
 	cachingTransport := httpcache.NewTransport(lruCache)
-	baseTransport := http.RoundTripper(cachingTransport)
+	cachingTransport.Transport = newInstrumentedTransport(http.DefaultTransport, installationID) // Cache hits never reach it
+	baseTransport := http.RoundTripper(newCacheObservingTransport(cachingTransport))
 
-	if config.Github.Token != "" {
+	if token != "" {
 		log.Println("Authenticating with GitHub Token.")
-		ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: config.Github.Token})
+		mode = "token"
+		ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
 		authContext := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Transport: baseTransport})
 		httpClient = oauth2.NewClient(authContext, ts)
-	} else if config.Github.AppID != 0 && config.Github.AppInstallationID != 0 && config.Github.AppPrivateKey != "" {
+	} else if config.Github.AppID != 0 && config.Github.AppInstallationID != 0 && len(appKeyFiles) > 0 {
 		log.Println("Authenticating with GitHub App.")
-		appTransport, err := ghinstallation.NewKeyFromFile(baseTransport, config.Github.AppID, config.Github.AppInstallationID, config.Github.AppPrivateKey)
-		if err != nil {
-			return nil, fmt.Errorf("GitHub App authentication setup failed: %w", err)
-		}
+		mode = "app"
+		apiURL := ""
 		if config.Github.APIURL != "" && config.Github.APIURL != "api.github.com" {
 			// Ensure config.Github.APIURL is the GHE API base, e.g., "https://my.ghe.com/api/v3"
-			// The ghinstallation transport expects this to correctly form token URLs.
-			appTransport.BaseURL = strings.TrimSuffix(config.Github.APIURL, "/")
-			log.Printf("GitHub App transport BaseURL set for GHE: %s", appTransport.BaseURL)
+			apiURL = strings.TrimSuffix(config.Github.APIURL, "/")
+			log.Printf("GitHub App transport BaseURL set for GHE: %s", apiURL)
+		}
+		if len(appKeyFiles) == 1 {
+			appTransport, err := ghinstallation.NewKeyFromFile(baseTransport, config.Github.AppID, config.Github.AppInstallationID, appKeyFiles[0])
+			if err != nil {
+				return nil, fmt.Errorf("GitHub App authentication setup failed: %w", err)
+			}
+			if apiURL != "" {
+				// The ghinstallation transport expects this to correctly form token URLs.
+				appTransport.BaseURL = apiURL
+			}
+			httpClient = &http.Client{Transport: appTransport}
+		} else {
+			log.Printf("GitHub App has %d private keys, falling back to the next one when a key is rejected.", len(appKeyFiles))
+			appTransport, err := newAppKeyTransport(baseTransport, appKeyFiles, apiURL)
+			if err != nil {
+				return nil, fmt.Errorf("GitHub App authentication setup failed: %w", err)
+			}
+			httpClient = &http.Client{Transport: appTransport}
 		}
-		httpClient = &http.Client{Transport: appTransport}
 	} else {
 		log.Println("No GitHub Token or App credentials provided. Using unauthenticated client (limited rate). Caching will still apply.")
+		mode = "anonymous"
 		httpClient = &http.Client{Transport: baseTransport}
 	}
 
@@ -151,5 +487,21 @@ func NewClient() (*github.Client, error) {
 	if errGHClient != nil {
 		return nil, fmt.Errorf("GitHub client creation failed: %w", errGHClient)
 	}
+
+	clientAuthMu.Lock()
+	authMode = mode
+	clientAuthMu.Unlock()
 	return ghClient, nil
+}
+
+// getClient returns the current GitHub client, nil before InitMetrics created it.
+func getClient() *github.Client {
+	return currentClient.Load()
+}
+
+// getAuthMode returns how the current GitHub client authenticates.
+func getAuthMode() string {
+	clientAuthMu.RLock()
+	defer clientAuthMu.RUnlock()
+	return authMode
 }
\ No newline at end of file
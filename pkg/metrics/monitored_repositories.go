@@ -0,0 +1,50 @@
+package metrics
+
+// How a monitored repository was found, reported by MonitoredRepositories.
+const (
+	repoSourceRepositories = "repositories" // Explicitly configured in GITHUB_REPOS
+	repoSourceOrganization = "organization" // Discovered in an organization
+)
+
+type repoSource struct {
+	source       string
+	organization string // Set for repoSourceOrganization
+}
+
+// Key: "owner/repo", Value: how it was found by the last refresh. Guarded by workflowsMu.
+var repoSources = make(map[string]repoSource)
+
+// MonitoredRepository describes a repository monitored by the exporter, for the /debug/repositories endpoint.
+type MonitoredRepository struct {
+	Repo          string `json:"repo"`
+	Source        string `json:"source,omitempty"`       // "repositories" or "organization", empty before the first refresh
+	Organization  string `json:"organization,omitempty"` // Organization the repository was discovered in
+	Workflows     string `json:"workflows"`              // "found", "none" or "unknown" (not fetched yet, failed or evicted)
+	WorkflowCount int    `json:"workflow_count"`
+}
+
+// MonitoredRepositories returns the repositories currently monitored, with their discovery provenance and
+// whether their workflows were found, answering "is my repository actually being scraped?".
+func MonitoredRepositories() []MonitoredRepository {
+	repos := repositories
+	workflowsMu.RLock()
+	defer workflowsMu.RUnlock()
+
+	monitored := make([]MonitoredRepository, 0, len(repos))
+	for _, repoFullName := range repos {
+		repo := MonitoredRepository{
+			Repo:         repoFullName,
+			Source:       repoSources[repoFullName].source,
+			Organization: repoSources[repoFullName].organization,
+			Workflows:    "unknown",
+		}
+		if repoWorkflows, ok := workflows[repoFullName]; ok {
+			repo.Workflows = "found"
+			repo.WorkflowCount = len(repoWorkflows)
+		} else if reposWithoutWorkflows[repoFullName] {
+			repo.Workflows = "none"
+		}
+		monitored = append(monitored, repo)
+	}
+	return monitored
+}
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/common/model"
+)
+
+// Applied to the path of the workflow of each run (PATH_DERIVED_LABEL_REGEX), set by InitMetrics. Each named
+// capture group becomes a label of the workflow run metrics, after the fields of EXPORT_FIELDS_WORKFLOW_RUN.
+var pathDerivedLabelPattern *regexp.Regexp
+
+// compilePathDerivedLabelPattern compiles PATH_DERIVED_LABEL_REGEX. It returns nil when no regex is configured.
+func compilePathDerivedLabelPattern(expr string) (*regexp.Regexp, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	knownFields := make(map[string]bool)
+	for _, fieldName := range append(append([]string{}, directFieldNames...), derivedFieldNames...) {
+		knownFields[fieldName] = true
+	}
+	labelNames := getPathDerivedLabelNames(pattern)
+	if len(labelNames) == 0 {
+		return nil, fmt.Errorf("%q has no named capture group like (?P<service>...)", expr)
+	}
+	for _, labelName := range labelNames {
+		if !model.LabelName(labelName).IsValid() || strings.HasPrefix(labelName, "__") {
+			return nil, fmt.Errorf("capture group %q is not a valid label name", labelName)
+		}
+		if knownFields[labelName] {
+			return nil, fmt.Errorf("capture group %q has the name of a workflow run field", labelName)
+		}
+	}
+	return pattern, nil
+}
+
+// getPathDerivedLabelNames returns the names of the named capture groups of a pattern, in their order.
+func getPathDerivedLabelNames(pattern *regexp.Regexp) []string {
+	if pattern == nil {
+		return nil
+	}
+	var labelNames []string
+	for _, name := range pattern.SubexpNames() {
+		if name != "" {
+			labelNames = append(labelNames, name)
+		}
+	}
+	return labelNames
+}
+
+// getPathDerivedLabels returns the value of each named capture group of PATH_DERIVED_LABEL_REGEX in the path of a
+// workflow, like .github/workflows/service-a-ci.yml. Values are empty when the path doesn't match.
+func getPathDerivedLabels(workflowPath string) map[string]string {
+	if pathDerivedLabelPattern == nil {
+		return nil
+	}
+	labels := make(map[string]string)
+	match := pathDerivedLabelPattern.FindStringSubmatch(workflowPath)
+	for i, name := range pathDerivedLabelPattern.SubexpNames() {
+		if name == "" {
+			continue
+		}
+		labels[name] = ""
+		if match != nil {
+			labels[name] = match[i]
+		}
+	}
+	return labels
+}
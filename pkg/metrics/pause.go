@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"log"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	exporterPausedGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "github_exporter_paused",
+			Help: "1 while metric collection is paused through /admin/pause, 0 otherwise.",
+		},
+	)
+
+	// Checked at the top of each fetcher cycle, which is skipped while it is set.
+	collectionPaused atomic.Bool
+)
+
+// PauseCollection stops the fetchers from calling the GitHub API, e.g. during a GitHub incident.
+// Cycles already running finish, later ones are skipped until ResumeCollection. Metrics keep their last values.
+func PauseCollection() {
+	if collectionPaused.CompareAndSwap(false, true) {
+		exporterPausedGauge.Set(1)
+		log.Println("Metric collection paused.")
+	}
+}
+
+// ResumeCollection undoes PauseCollection. Fetchers resume on their next tick.
+func ResumeCollection() {
+	if collectionPaused.CompareAndSwap(true, false) {
+		exporterPausedGauge.Set(0)
+		log.Println("Metric collection resumed.")
+	}
+}
+
+// isCollectionPaused reports whether fetcher cycles should be skipped.
+func isCollectionPaused() bool {
+	return collectionPaused.Load()
+}
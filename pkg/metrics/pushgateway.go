@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+const pushgatewayAttempts = 3
+
+// getPushgatewayInstance returns the instance grouping label, defaulting to the hostname.
+func getPushgatewayInstance() string {
+	if config.Pushgateway.Instance != "" {
+		return config.Pushgateway.Instance
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "github-actions-exporter"
+	}
+	return hostname
+}
+
+// pushToPushgateway replaces the metrics of this exporter's group on the Pushgateway with the current registry.
+// It does nothing when PUSHGATEWAY_URL is not set.
+func pushToPushgateway() {
+	if config.Pushgateway.URL == "" {
+		return
+	}
+
+	pusher := push.New(config.Pushgateway.URL, config.Pushgateway.Job).
+		Gatherer(prometheus.DefaultGatherer).
+		Grouping("instance", getPushgatewayInstance())
+
+	var err error
+	for attempt := 1; attempt <= pushgatewayAttempts; attempt++ {
+		if err = pusher.Push(); err == nil {
+			return
+		}
+		log.Printf("Push to Pushgateway %s failed: %v (attempt %d)", config.Pushgateway.URL, err, attempt)
+		if attempt < pushgatewayAttempts {
+			time.Sleep(time.Duration(attempt) * 2 * time.Second)
+		}
+	}
+	log.Printf("Giving up pushing to Pushgateway %s after %d attempts.", config.Pushgateway.URL, pushgatewayAttempts)
+}
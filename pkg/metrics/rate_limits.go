@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/google/go-github/v72/github"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+)
+
+// Slept when a rate limit reset time is already past, e.g. with clock skew, instead of retrying immediately.
+const rateLimitPastResetSleep = 5 * time.Second
+
+var (
+	apiRateLimitGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_api_rate_limit",
+			Help: "Rate limit of a GitHub API resource (core, search, code_search, graphql) detected at startup, in requests per window. " +
+				"+Inf when rate limiting is disabled, as GitHub Enterprise Server allows.",
+		},
+		[]string{"resource"},
+	)
+
+	// Detected by detectRateLimits, nil when unknown. Read-only afterwards.
+	detectedRateLimits *github.RateLimits
+
+	// Set by detectRateLimits when the API reports rate limiting as disabled (GitHub Enterprise Server).
+	rateLimitingDisabled bool
+)
+
+// detectRateLimits learns the actual rate limits of the API, which differ from github.com on GitHub Enterprise
+// Server and may even be disabled there, in which case /rate_limit answers 404. The limits are exported and
+// used to adapt the spacing of calls that would otherwise assume github.com limits (see getSearchRequestInterval).
+func detectRateLimits() {
+	limits, _, err := getClient().RateLimit.Get(fetcherContext(fetcherDiscovery))
+	recordAPIError(err)
+	var responseErr *github.ErrorResponse
+	switch {
+	case errors.As(err, &responseErr) && responseErr.Response != nil && responseErr.Response.StatusCode == http.StatusNotFound:
+		log.Println("GitHub API rate limiting is disabled. Calls are not spaced out.")
+		rateLimitingDisabled = true
+		for _, resource := range []string{"core", "search", "code_search", "graphql"} {
+			apiRateLimitGauge.WithLabelValues(resource).Set(math.Inf(1))
+		}
+		return
+	case err != nil:
+		log.Printf("Detecting the GitHub API rate limits failed, assuming github.com limits: %v", err)
+		return
+	}
+
+	detectedRateLimits = limits
+	for resource, rate := range map[string]*github.Rate{
+		"core":        limits.Core,
+		"search":      limits.Search,
+		"code_search": limits.CodeSearch,
+		"graphql":     limits.GraphQL,
+	} {
+		if rate != nil {
+			apiRateLimitGauge.WithLabelValues(resource).Set(float64(rate.Limit))
+		}
+	}
+	if limits.Core != nil {
+		log.Printf("Detected GitHub API rate limits: %d core requests per hour.", limits.Core.Limit)
+	}
+}
+
+// sleepUntilRateLimitReset pauses a fetcher until the reset time of a rate limit. The duration is capped by
+// MAX_RATE_LIMIT_SLEEP_SECONDS, so that a skewed clock or a bogus reset time doesn't stall the fetcher for hours.
+func sleepUntilRateLimitReset(reset time.Time) {
+	sleep := time.Until(reset)
+	if sleep <= 0 {
+		sleep = rateLimitPastResetSleep
+	}
+	if maxSleep := time.Duration(config.Github.MaxRateLimitSleepSeconds) * time.Second; maxSleep > 0 && sleep > maxSleep {
+		log.Printf("Rate limit reset at %s is %s away, only pausing for %s.", reset, sleep.Round(time.Second), maxSleep)
+		sleep = maxSleep
+	}
+	time.Sleep(sleep)
+}
+
+// getSearchRequestInterval returns the spacing of code search calls: none when rate limiting is disabled,
+// else a minute divided by the detected code search limit, else searchRequestInterval (github.com limit).
+func getSearchRequestInterval() time.Duration {
+	if rateLimitingDisabled {
+		return 0
+	}
+	if detectedRateLimits != nil {
+		rate := detectedRateLimits.CodeSearch
+		if rate == nil {
+			rate = detectedRateLimits.Search
+		}
+		if rate != nil && rate.Limit > 0 {
+			return time.Minute / time.Duration(rate.Limit)
+		}
+	}
+	return searchRequestInterval
+}
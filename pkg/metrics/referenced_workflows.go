@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"github.com/google/go-github/v72/github"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// maxReferencedWorkflowsPerRun bounds the series a single run can create,
+// since a run may call many reusable workflows.
+const maxReferencedWorkflowsPerRun = 20
+
+var (
+	workflowRunReferencedGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_workflow_run_referenced",
+			Help: "Reusable workflows referenced by runs of a caller workflow in the fetch window (always 1).",
+		},
+		[]string{"repo", "caller_workflow", "referenced_path", "referenced_sha"},
+	)
+
+	reusableWorkflowUsageGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_reusable_workflow_usage",
+			Help: "Number of distinct caller workflows, across all monitored repositories, with runs in the fetch window referencing a reusable workflow.",
+		},
+		[]string{"referenced_path"},
+	)
+)
+
+// reusableWorkflowCallers collects the caller workflows of each reusable workflow over a collection cycle.
+// Key: referenced path.
+type reusableWorkflowCallers map[string]map[workflowKey]bool
+
+// add records the reusable workflows referenced by a run, within the bound of setReferencedWorkflows.
+func (c reusableWorkflowCallers) add(repoFullName string, callerWorkflow string, run *github.WorkflowRun) {
+	for i, referenced := range run.ReferencedWorkflows {
+		if i >= maxReferencedWorkflowsPerRun {
+			break
+		}
+		if referenced == nil || referenced.GetPath() == "" {
+			continue
+		}
+		if c[referenced.GetPath()] == nil {
+			c[referenced.GetPath()] = make(map[workflowKey]bool)
+		}
+		c[referenced.GetPath()][workflowKey{repoFullName, callerWorkflow}] = true
+	}
+}
+
+// export replaces reusableWorkflowUsageGauge with the callers counted this cycle, so reusable workflows
+// no longer referenced disappear.
+func (c reusableWorkflowCallers) export() {
+	reusableWorkflowUsageGauge.Reset()
+	for referencedPath, callers := range c {
+		reusableWorkflowUsageGauge.WithLabelValues(referencedPath).Set(float64(len(callers)))
+	}
+}
+
+// setReferencedWorkflows emits one info series per reusable workflow referenced by a run.
+func setReferencedWorkflows(repoFullName string, callerWorkflow string, run *github.WorkflowRun) {
+	for i, referenced := range run.ReferencedWorkflows {
+		if i >= maxReferencedWorkflowsPerRun {
+			break
+		}
+		if referenced == nil || referenced.GetPath() == "" {
+			continue
+		}
+		workflowRunReferencedGauge.WithLabelValues(repoFullName, callerWorkflow, referenced.GetPath(), referenced.GetSHA()).Set(1)
+	}
+}
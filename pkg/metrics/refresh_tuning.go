@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"time"
+)
+
+// refreshHeadroom is applied on top of the estimated cycle duration so a cycle
+// slightly slower than average still completes before the next tick.
+const refreshHeadroom = 1.2
+
+// cycleDurationTracker keeps the running average time spent per repository in a collection cycle,
+// which is used to estimate the minimum feasible refresh interval for a given number of repositories.
+type cycleDurationTracker struct {
+	cycles     int
+	avgPerRepo time.Duration
+}
+
+// observe records the duration of a completed cycle over repoCount repositories.
+func (t *cycleDurationTracker) observe(cycleDuration time.Duration, repoCount int) {
+	if repoCount <= 0 {
+		return
+	}
+	perRepo := cycleDuration / time.Duration(repoCount)
+	t.cycles++
+	t.avgPerRepo += (perRepo - t.avgPerRepo) / time.Duration(t.cycles)
+}
+
+// minimumRefresh estimates the shortest refresh interval a cycle over repoCount repositories fits in.
+// It returns 0 until at least one cycle has been observed.
+func (t *cycleDurationTracker) minimumRefresh(repoCount int) time.Duration {
+	if t.cycles == 0 {
+		return 0
+	}
+	estimate := time.Duration(float64(t.avgPerRepo) * float64(repoCount) * refreshHeadroom)
+	return estimate.Round(time.Second)
+}
@@ -0,0 +1,147 @@
+package metrics
+
+import (
+	"maps"
+
+	"github.com/google/go-github/v72/github"
+)
+
+// runAggregates holds what the runs of one repository contributed to the metrics aggregated over a workflow run
+// cycle. It is only built from a complete fetch, and exported together with the other repositories of the cycle.
+type runAggregates struct {
+	runIDs             map[int64]bool
+	headSHAs           map[string]bool // Head commits of push runs resolved to a pull request
+	jobRunnerTypes     map[jobRunnerTypeKey]int
+	runOSes            map[runOSKey]int
+	failureClasses     map[runFailureClassKey]int
+	overheads          map[workflowKey]runOverheadSum
+	billableSeconds    map[runOSKey]float64 // Key os: runner environment from the usage API (UBUNTU, MACOS, ...)
+	waitingSeconds     map[workflowRunWaitingKey]float64
+	queuedJobs         map[string]int // Key: requested runner labels
+	hostedQueueDepths  map[string]int // Key: organization
+	runsPerSHA         runsPerSHACounter
+	concurrencyPending map[workflowKey]int
+	latestRuns         latestRunTracker
+	latestCompleted    latestCompletedRunTracker
+	startDelays        runStartDelayTracker
+	checkRuns          checkRunsCollector
+	activeWorkflows    activeWorkflowsCounter
+	reusableCallers    reusableWorkflowCallers
+	infoRuns           []infoRun
+}
+
+// infoRun is an exported run whose links, rerun and referenced workflow info series are set.
+type infoRun struct {
+	repo         string
+	workflowName string
+	run          *github.WorkflowRun
+}
+
+func newRunAggregates() *runAggregates {
+	return &runAggregates{
+		runIDs:             make(map[int64]bool),
+		headSHAs:           make(map[string]bool),
+		jobRunnerTypes:     make(map[jobRunnerTypeKey]int),
+		runOSes:            make(map[runOSKey]int),
+		failureClasses:     make(map[runFailureClassKey]int),
+		overheads:          make(map[workflowKey]runOverheadSum),
+		billableSeconds:    make(map[runOSKey]float64),
+		waitingSeconds:     make(map[workflowRunWaitingKey]float64),
+		queuedJobs:         make(map[string]int),
+		hostedQueueDepths:  make(map[string]int),
+		runsPerSHA:         make(runsPerSHACounter),
+		concurrencyPending: make(map[workflowKey]int),
+		latestRuns:         make(latestRunTracker),
+		latestCompleted:    make(latestCompletedRunTracker),
+		startDelays:        make(runStartDelayTracker),
+		checkRuns:          make(checkRunsCollector),
+		activeWorkflows:    make(activeWorkflowsCounter),
+		reusableCallers:    make(reusableWorkflowCallers),
+	}
+}
+
+// merge adds the aggregates of another repository. Keys carry the repository, except the queued jobs
+// (per runner labels), hosted queue depths (per organization) and reusable workflow callers (per referenced path).
+func (a *runAggregates) merge(other *runAggregates) {
+	maps.Copy(a.runIDs, other.runIDs)
+	maps.Copy(a.headSHAs, other.headSHAs)
+	maps.Copy(a.jobRunnerTypes, other.jobRunnerTypes)
+	maps.Copy(a.runOSes, other.runOSes)
+	maps.Copy(a.failureClasses, other.failureClasses)
+	maps.Copy(a.overheads, other.overheads)
+	maps.Copy(a.billableSeconds, other.billableSeconds)
+	maps.Copy(a.waitingSeconds, other.waitingSeconds)
+	for labels, count := range other.queuedJobs {
+		a.queuedJobs[labels] += count
+	}
+	for org, count := range other.hostedQueueDepths {
+		a.hostedQueueDepths[org] += count
+	}
+	maps.Copy(a.runsPerSHA, other.runsPerSHA)
+	maps.Copy(a.concurrencyPending, other.concurrencyPending)
+	maps.Copy(a.latestRuns, other.latestRuns)
+	maps.Copy(a.latestCompleted, other.latestCompleted)
+	maps.Copy(a.startDelays, other.startDelays)
+	maps.Copy(a.checkRuns, other.checkRuns)
+	maps.Copy(a.activeWorkflows, other.activeWorkflows)
+	for referencedPath, callers := range other.reusableCallers {
+		if a.reusableCallers[referencedPath] == nil {
+			a.reusableCallers[referencedPath] = make(map[workflowKey]bool)
+		}
+		maps.Copy(a.reusableCallers[referencedPath], callers)
+	}
+	a.infoRuns = append(a.infoRuns, other.infoRuns...)
+}
+
+// exportInfo replaces the links, rerun and referenced workflow info series with those of the exported runs.
+func (a *runAggregates) exportInfo() {
+	resetWorkflowRunInfo()
+	workflowRunReferencedGauge.Reset()
+	for _, info := range a.infoRuns {
+		setWorkflowRunInfo(info.repo, info.run)
+		if len(info.run.ReferencedWorkflows) > 0 {
+			setReferencedWorkflows(info.repo, info.workflowName, info.run)
+		}
+	}
+}
+
+// repoAggregateTracker remembers the aggregates of each repository across workflow run cycles, like
+// repoSeriesTracker does for the status and duration series, so a repository that wasn't fully fetched
+// (carried over, partial, deferred or dormant) keeps contributing its last known aggregates.
+type repoAggregateTracker struct {
+	previous map[string]*runAggregates
+	current  map[string]*runAggregates
+}
+
+func newRepoAggregateTracker() *repoAggregateTracker {
+	return &repoAggregateTracker{
+		previous: make(map[string]*runAggregates),
+		current:  make(map[string]*runAggregates),
+	}
+}
+
+// replaceRepo starts new aggregates for a repository fetched completely during the current cycle.
+func (t *repoAggregateTracker) replaceRepo(repo string) *runAggregates {
+	aggregates := newRunAggregates()
+	t.current[repo] = aggregates
+	return aggregates
+}
+
+// keepRepo carries the aggregates of a repository over to the current cycle untouched.
+func (t *repoAggregateTracker) keepRepo(repo string) {
+	if aggregates, ok := t.previous[repo]; ok {
+		t.current[repo] = aggregates
+	}
+}
+
+// finishCycle returns the aggregates of all the repositories replaced or kept during the current cycle,
+// forgets the others (e.g. no longer monitored) and starts a new cycle.
+func (t *repoAggregateTracker) finishCycle() *runAggregates {
+	merged := newRunAggregates()
+	for _, aggregates := range t.current {
+		merged.merge(aggregates)
+	}
+	t.previous = t.current
+	t.current = make(map[string]*runAggregates)
+	return merged
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v72/github"
+)
+
+func TestRepoAggregateTrackerKeepRepo(t *testing.T) {
+	tracker := newRepoAggregateTracker()
+	for _, repo := range []string{"org/small", "org/big"} {
+		aggregates := tracker.replaceRepo(repo)
+		aggregates.latestRuns.add(repo, "ci", &github.WorkflowRun{ID: github.Ptr(int64(1)), HeadBranch: github.Ptr("main")})
+		aggregates.queuedJobs["ubuntu-latest"]++
+		aggregates.infoRuns = append(aggregates.infoRuns, infoRun{repo, "ci", &github.WorkflowRun{ID: github.Ptr(int64(1))}})
+	}
+	tracker.finishCycle()
+
+	// org/big is carried over to the next cycle
+	tracker.replaceRepo("org/small").queuedJobs["ubuntu-latest"]++
+	tracker.keepRepo("org/big")
+	tracker.keepRepo("org/new") // Never fetched completely, nothing to keep
+	cycle := tracker.finishCycle()
+
+	if _, ok := cycle.latestRuns[latestRunKey{"org/big", "ci", "main"}]; !ok {
+		t.Errorf("latest run of the kept repository was dropped")
+	}
+	if _, ok := cycle.latestRuns[latestRunKey{"org/small", "ci", "main"}]; ok {
+		t.Errorf("latest run of the replaced repository was kept")
+	}
+	if got := cycle.queuedJobs["ubuntu-latest"]; got != 2 {
+		t.Errorf("queued jobs = %d, want 2 (one per repository)", got)
+	}
+	if len(cycle.infoRuns) != 1 || cycle.infoRuns[0].repo != "org/big" {
+		t.Errorf("info runs = %v, want the run of org/big only", cycle.infoRuns)
+	}
+
+	cycle = tracker.finishCycle()
+	if len(cycle.latestRuns) != 0 || len(cycle.queuedJobs) != 0 {
+		t.Errorf("aggregates of repositories neither replaced nor kept were not forgotten: %+v", cycle)
+	}
+}
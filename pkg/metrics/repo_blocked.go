@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"github.com/google/go-github/v72/github"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Values of the reason label of github_repo_actions_blocked.
+const (
+	repoBlockedArchived  = "archived"
+	repoBlockedDisabled  = "disabled"
+	repoBlockedSuspended = "suspended"
+)
+
+var repoActionsBlockedGauge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "github_repo_actions_blocked",
+		Help: "Set to 1 for a monitored repository whose workflows can't run: archived, disabled by GitHub, " +
+			"or suspended when all its workflows are disabled (manually or after a period of inactivity).",
+	},
+	[]string{"repo", "reason"},
+)
+
+// getRepoBlockedReason returns why the workflows of a repository can't run, or "" when they can.
+// repo is nil when its metadata is unknown, repoWorkflows when its workflow definitions are unknown or empty.
+func getRepoBlockedReason(repo *github.Repository, repoWorkflows map[int64]*github.Workflow) string {
+	switch {
+	case repo.GetArchived():
+		return repoBlockedArchived
+	case repo.GetDisabled():
+		return repoBlockedDisabled
+	}
+	if len(repoWorkflows) == 0 {
+		return ""
+	}
+	for _, wf := range repoWorkflows {
+		if wf.GetState() == "active" {
+			return ""
+		}
+	}
+	return repoBlockedSuspended
+}
+
+// updateRepoActionsBlocked correlates the repository metadata with the workflow definitions fetched by a
+// refresh to set repoActionsBlockedGauge, distinguishing repositories without runs from dead ones.
+func updateRepoActionsBlocked(repos []string, metadata map[string]*github.Repository, workflowsByRepo map[string]map[int64]*github.Workflow) {
+	repoActionsBlockedGauge.Reset()
+	for _, repoFullName := range repos {
+		if reason := getRepoBlockedReason(metadata[repoFullName], workflowsByRepo[repoFullName]); reason != "" {
+			repoActionsBlockedGauge.WithLabelValues(repoFullName, reason).Set(1)
+		}
+	}
+}
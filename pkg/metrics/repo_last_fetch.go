@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	repoLastFetchGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_repo_last_fetch_timestamp_seconds",
+			Help: "Unix timestamp of the last complete fetch of the workflow runs of a repository. " +
+				"A repository failing while others succeed (e.g. lost permissions) stops updating it.",
+		},
+		[]string{"repo"},
+	)
+
+	// Repositories with a repoLastFetchGauge series, only accessed by the workflow run collector.
+	reposWithLastFetch = make(map[string]bool)
+)
+
+// setRepoLastFetch records a complete fetch of the workflow runs of a repository.
+func setRepoLastFetch(repoFullName string) {
+	repoLastFetchGauge.WithLabelValues(repoFullName).SetToCurrentTime()
+	reposWithLastFetch[repoFullName] = true
+}
+
+// pruneRepoLastFetch deletes the series of repositories that are no longer monitored.
+func pruneRepoLastFetch(monitoredRepos []string) {
+	monitored := make(map[string]bool, len(monitoredRepos))
+	for _, repoFullName := range monitoredRepos {
+		monitored[repoFullName] = true
+	}
+	for repoFullName := range reposWithLastFetch {
+		if !monitored[repoFullName] {
+			repoLastFetchGauge.DeleteLabelValues(repoFullName)
+			delete(reposWithLastFetch, repoFullName)
+		}
+	}
+}
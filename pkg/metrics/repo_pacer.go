@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	repoFetchPacingGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "github_workflow_run_fetch_pacing_seconds",
+			Help: "Interval between two repository fetches of the workflow run collection cycle (0 when fetches are not spread).",
+		},
+	)
+)
+
+// repoPacer spreads the repository fetches of a cycle evenly across the refresh interval,
+// so API calls are smoothed instead of bursting at the top of each tick.
+type repoPacer struct {
+	ticker  *time.Ticker
+	started bool
+}
+
+// newRepoPacer returns a pacer for repoCount repositories. It never waits when
+// SPREAD_REPO_FETCHES is disabled or there is a single repository.
+func newRepoPacer(refreshInterval time.Duration, repoCount int) *repoPacer {
+	p := &repoPacer{}
+	var pace time.Duration
+	if config.Github.SpreadRepoFetches && repoCount > 1 {
+		pace = refreshInterval / time.Duration(repoCount)
+	}
+	if pace > 0 {
+		p.ticker = time.NewTicker(pace)
+	}
+	repoFetchPacingGauge.Set(pace.Seconds())
+	return p
+}
+
+// wait blocks until the next repository may be fetched and returns the time spent waiting.
+// The first repository of a cycle is fetched immediately.
+func (p *repoPacer) wait() time.Duration {
+	if p.ticker == nil || !p.started {
+		p.started = true
+		return 0
+	}
+	start := time.Now()
+	<-p.ticker.C
+	return time.Since(start)
+}
+
+func (p *repoPacer) stop() {
+	if p.ticker != nil {
+		p.ticker.Stop()
+	}
+}
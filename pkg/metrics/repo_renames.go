@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/v72/github"
+)
+
+var (
+	// Key: "owner/repo" as configured, Value: the canonical full name returned by the API.
+	// GitHub redirects the API calls of renamed (or transferred) repositories, so they keep working
+	// under the old name, but their metrics are exported under the canonical one.
+	repoRenames   = make(map[string]string)
+	repoRenamesMu sync.Mutex
+)
+
+// recordRepoRename remembers that a configured repository name resolves to another canonical name.
+func recordRepoRename(configuredName string, canonicalName string) {
+	repoRenamesMu.Lock()
+	defer repoRenamesMu.Unlock()
+	if repoRenames[configuredName] == canonicalName {
+		return
+	}
+	repoRenames[configuredName] = canonicalName
+	log.Printf("Repository %s was renamed (or transferred) to %s. Exporting its metrics under the new name, please update the configuration.", configuredName, canonicalName)
+}
+
+// getCanonicalRepoName returns the canonical name of a configured repository, itself when it wasn't renamed.
+// Successive renames are followed.
+func getCanonicalRepoName(configuredName string) string {
+	repoRenamesMu.Lock()
+	defer repoRenamesMu.Unlock()
+	canonicalName := configuredName
+	for hops := 0; hops < len(repoRenames); hops++ { // Bounded in case names were swapped
+		renamed, ok := repoRenames[canonicalName]
+		if !ok {
+			break
+		}
+		canonicalName = renamed
+	}
+	return canonicalName
+}
+
+// getRunsRepoFullName returns the full name of the repository the runs belong to, as returned by the API,
+// or "" when there are no runs to tell.
+func getRunsRepoFullName(runs []*github.WorkflowRun) string {
+	for _, run := range runs {
+		if run != nil && run.GetRepository().GetFullName() != "" {
+			return run.GetRepository().GetFullName()
+		}
+	}
+	return ""
+}
+
+// splitRepoFullName splits "owner/repo" into its owner and name.
+func splitRepoFullName(repoFullName string) (string, string) {
+	owner, repoName, _ := strings.Cut(repoFullName, "/")
+	return owner, repoName
+}
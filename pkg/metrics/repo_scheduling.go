@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+)
+
+// Values of REPO_SCHEDULING.
+const (
+	repoSchedulingConfig        = "config"
+	repoSchedulingSmallestFirst = "smallest_first"
+)
+
+// Weight of the runs of the last fetch in the average of a repository, smoothing out CI storms.
+const repoRunsAverageWeight = 0.3
+
+// Key: "owner/repo", Value: moving average of the number of runs listed per fetch. Only used by the
+// workflow run collection goroutine.
+var repoAverageRuns = make(map[string]float64)
+
+// Repositories the last smallest_first cycle didn't reach within the refresh interval, fetched first by the next
+// one. Only used by the workflow run collection goroutine.
+var carriedOverRepos []string
+
+// validateRepoScheduling checks the value of REPO_SCHEDULING.
+func validateRepoScheduling(scheduling string) error {
+	switch scheduling {
+	case repoSchedulingConfig, repoSchedulingSmallestFirst:
+		return nil
+	}
+	return fmt.Errorf("unknown scheduling %q, expected %s or %s", scheduling, repoSchedulingConfig, repoSchedulingSmallestFirst)
+}
+
+// recordRepoRuns updates the average number of runs of a repository after a complete fetch.
+func recordRepoRuns(repoFullName string, runs int) {
+	average, ok := repoAverageRuns[repoFullName]
+	if !ok {
+		repoAverageRuns[repoFullName] = float64(runs)
+		return
+	}
+	repoAverageRuns[repoFullName] = average + repoRunsAverageWeight*(float64(runs)-average)
+}
+
+// scheduleRepositories orders the repositories of a workflow run cycle. With smallest_first, repositories
+// expected to list the fewest runs come first, so a few giant repositories don't delay the refresh of all the
+// others, and keep the order of repos among equals (e.g. the rotation of the run budget). Repositories never
+// fetched count as empty, so newly discovered ones are fetched early. Repositories carried over by the previous
+// cycle (see carryOverRepositories) come before all the others. Until fetched, they keep their last known metrics.
+func scheduleRepositories(repos []string) []string {
+	if config.Github.RepoScheduling != repoSchedulingSmallestFirst {
+		return repos
+	}
+	monitored := make(map[string]bool, len(repos))
+	for _, repoFullName := range repos {
+		monitored[repoFullName] = true
+	}
+	for repoFullName := range repoAverageRuns { // Forget the repositories no longer monitored
+		if !monitored[repoFullName] {
+			delete(repoAverageRuns, repoFullName)
+		}
+	}
+	scheduled := append([]string{}, repos...)
+	sort.SliceStable(scheduled, func(i, j int) bool {
+		return repoAverageRuns[scheduled[i]] < repoAverageRuns[scheduled[j]]
+	})
+
+	carried := make(map[string]bool, len(carriedOverRepos))
+	var first []string
+	for _, repoFullName := range carriedOverRepos {
+		if monitored[repoFullName] && !carried[repoFullName] {
+			carried[repoFullName] = true
+			first = append(first, repoFullName)
+		}
+	}
+	carriedOverRepos = nil
+	for _, repoFullName := range scheduled {
+		if !carried[repoFullName] {
+			first = append(first, repoFullName)
+		}
+	}
+	return first
+}
+
+// isCycleOverdue reports whether a smallest_first cycle spent more than refreshInterval fetching, pacing excluded.
+// Its remaining repositories are then carried over to the next cycle rather than delaying it.
+func isCycleOverdue(fetchDuration time.Duration, refreshInterval time.Duration) bool {
+	return config.Github.RepoScheduling == repoSchedulingSmallestFirst && refreshInterval > 0 && fetchDuration > refreshInterval
+}
+
+// carryOverRepositories records the repositories an overdue cycle didn't reach, for scheduleRepositories.
+func carryOverRepositories(repos []string) {
+	carriedOverRepos = append([]string{}, repos...)
+}
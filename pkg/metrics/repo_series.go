@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// repoSeriesTracker remembers the label values each repository set on a group of gauges,
+// so the series of one repository can be replaced without resetting the whole gauge.
+// This keeps the last-known-good values of a repository whose fetch failed.
+type repoSeriesTracker struct {
+	gauges   []*prometheus.GaugeVec
+	previous map[string][][]string
+	current  map[string][][]string
+}
+
+func newRepoSeriesTracker(gauges ...*prometheus.GaugeVec) *repoSeriesTracker {
+	t := &repoSeriesTracker{
+		previous: make(map[string][][]string),
+		current:  make(map[string][][]string),
+	}
+	for _, gauge := range gauges {
+		if gauge != nil {
+			t.gauges = append(t.gauges, gauge)
+		}
+	}
+	return t
+}
+
+// useGauges switches the tracker to a new group of gauges, e.g. after ReloadWorkflowFields.
+// The series of the previous gauges are forgotten, as those gauges were unregistered.
+func (t *repoSeriesTracker) useGauges(gauges ...*prometheus.GaugeVec) {
+	var current []*prometheus.GaugeVec
+	for _, gauge := range gauges {
+		if gauge != nil {
+			current = append(current, gauge)
+		}
+	}
+	if len(current) == len(t.gauges) {
+		same := true
+		for i := range current {
+			same = same && current[i] == t.gauges[i]
+		}
+		if same {
+			return
+		}
+	}
+	t.gauges = current
+	t.previous = make(map[string][][]string)
+	t.current = make(map[string][][]string)
+}
+
+// replaceRepo deletes the series set by a repository during the previous cycle, before new ones are set.
+func (t *repoSeriesTracker) replaceRepo(repo string) {
+	t.deleteSeries(t.previous[repo])
+	t.current[repo] = nil
+}
+
+// keepRepo carries the series of a repository over to the current cycle untouched.
+func (t *repoSeriesTracker) keepRepo(repo string) {
+	t.current[repo] = t.previous[repo]
+}
+
+// add records label values set for a repository during the current cycle.
+func (t *repoSeriesTracker) add(repo string, labelValues []string) {
+	t.current[repo] = append(t.current[repo], labelValues)
+}
+
+// finishCycle deletes the series of repositories that were neither replaced nor kept
+// (e.g. no longer monitored) and starts a new cycle.
+func (t *repoSeriesTracker) finishCycle() {
+	for repo, series := range t.previous {
+		if _, ok := t.current[repo]; !ok {
+			t.deleteSeries(series)
+		}
+	}
+	t.previous = t.current
+	t.current = make(map[string][][]string)
+}
+
+func (t *repoSeriesTracker) deleteSeries(series [][]string) {
+	for _, labelValues := range series {
+		for _, gauge := range t.gauges {
+			gauge.DeleteLabelValues(labelValues...)
+		}
+	}
+}
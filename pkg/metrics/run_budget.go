@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"log"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	runsSkippedBudgetCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "github_runs_skipped_budget_total",
+			Help: "Number of workflow runs not exported because the MAX_RUNS_PER_CYCLE budget of the cycle was spent.",
+		},
+		[]string{"repo"},
+	)
+
+	// Offset of the first repository of the next cycle, so that the same repositories aren't always last.
+	runBudgetRotation int
+)
+
+// runBudget shares MAX_RUNS_PER_CYCLE among the repositories of a cycle. Each repository may export
+// at most its fair share of what remains, so one busy repository can't spend the whole budget, and
+// what it leaves unused goes to the next ones.
+type runBudget struct {
+	remaining int // Negative when unlimited
+	skipped   int
+}
+
+func newRunBudget(maxRuns int) *runBudget {
+	if maxRuns <= 0 {
+		return &runBudget{remaining: -1}
+	}
+	return &runBudget{remaining: maxRuns}
+}
+
+// repoShare returns the number of runs the next repository may export, -1 when unlimited.
+func (b *runBudget) repoShare(reposLeft int) int {
+	if b.remaining < 0 {
+		return -1
+	}
+	if reposLeft < 1 {
+		reposLeft = 1
+	}
+	return (b.remaining + reposLeft - 1) / reposLeft
+}
+
+// use records the runs exported by a repository.
+func (b *runBudget) use(runs int) {
+	if b.remaining >= 0 {
+		b.remaining -= runs
+	}
+}
+
+// skip records a run not exported because its repository spent its share.
+func (b *runBudget) skip(repoFullName string) {
+	b.skipped++
+	runsSkippedBudgetCounter.WithLabelValues(repoFullName).Inc()
+}
+
+func (b *runBudget) logSkipped() {
+	if b.skipped > 0 {
+		log.Printf("MAX_RUNS_PER_CYCLE reached: %d workflow runs were not exported this cycle.", b.skipped)
+	}
+}
+
+// rotateRepositories returns the repositories starting at a different one each cycle when the run budget is
+// enabled, so that the repositories processed last, with the least budget left, change between cycles.
+func rotateRepositories(repos []string, budget *runBudget) []string {
+	if budget.remaining < 0 || len(repos) == 0 {
+		return repos
+	}
+	offset := runBudgetRotation % len(repos)
+	runBudgetRotation++
+	return append(append([]string{}, repos[offset:]...), repos[:offset]...)
+}
@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/go-github/v72/github"
+)
+
+// Key: runner state (see getRunnerState) or raw runner status, Value: value of the runner status gauges.
+// States missing from the map are exported as 0. Overridden by RUNNER_STATUS_VALUE_MAP.
+var runnerStatusValues = map[string]float64{
+	"online": 1,
+	"idle":   1, // GitHub Enterprise Server
+	"active": 1, // GitHub Enterprise Server
+}
+
+// parseRunnerStatusValueMap parses a JSON object of runner state to value, like {"online-idle":1,"online-busy":2}.
+// An empty string keeps the default mapping.
+func parseRunnerStatusValueMap(statusValueMap string) (map[string]float64, error) {
+	if statusValueMap == "" {
+		return runnerStatusValues, nil
+	}
+	values := make(map[string]float64)
+	if err := json.Unmarshal([]byte(statusValueMap), &values); err != nil {
+		return nil, fmt.Errorf("not a JSON object of runner status to number: %w", err)
+	}
+	return values, nil
+}
+
+// getRunnerState refines the status of an online runner with its busy flag ("online-idle" or "online-busy").
+// Other statuses ("offline", GitHub Enterprise Server specific ones, ...) are returned as is.
+func getRunnerState(runner *github.Runner) string {
+	if runner.GetStatus() != "online" {
+		return runner.GetStatus()
+	}
+	if runner.GetBusy() {
+		return "online-busy"
+	}
+	return "online-idle"
+}
+
+// getRunnerStatusValue returns the gauge value of a runner, looking up its refined state first
+// and its raw status second, so a map only listing "online" still covers busy and idle runners.
+func getRunnerStatusValue(runner *github.Runner) float64 {
+	if value, ok := runnerStatusValues[getRunnerState(runner)]; ok {
+		return value
+	}
+	return runnerStatusValues[runner.GetStatus()]
+}
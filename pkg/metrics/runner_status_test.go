@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v72/github"
+)
+
+func TestParseRunnerStatusValueMap(t *testing.T) {
+	tests := []struct {
+		name           string
+		statusValueMap string
+		want           map[string]float64
+		wantErr        bool
+	}{
+		{"empty keeps the default mapping", "", runnerStatusValues, false},
+		{"refined states", `{"online-idle":1,"online-busy":2,"offline":0}`, map[string]float64{"online-idle": 1, "online-busy": 2, "offline": 0}, false},
+		{"empty object", `{}`, map[string]float64{}, false},
+		{"invalid JSON", `{"online":1`, nil, true},
+		{"not an object", `["online"]`, nil, true},
+		{"non-numeric value", `{"online":"up"}`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRunnerStatusValueMap(tt.statusValueMap)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRunnerStatusValueMap(%q) error = %v, wantErr %v", tt.statusValueMap, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseRunnerStatusValueMap(%q) = %v, want %v", tt.statusValueMap, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetRunnerStatusValue(t *testing.T) {
+	previousValues := runnerStatusValues
+	t.Cleanup(func() { runnerStatusValues = previousValues })
+
+	refined := map[string]float64{"online-idle": 1, "online-busy": 2, "online": 5, "offline": 0}
+	onlineOnly := map[string]float64{"online": 1}
+	tests := []struct {
+		name         string
+		statusValues map[string]float64
+		status       string
+		busy         bool
+		want         float64
+	}{
+		{"idle runner uses online-idle", refined, "online", false, 1},
+		{"busy runner uses online-busy", refined, "online", true, 2},
+		{"idle runner falls back to online", onlineOnly, "online", false, 1},
+		{"busy runner falls back to online", onlineOnly, "online", true, 1},
+		{"offline runner uses its status", refined, "offline", false, 0},
+		{"busy offline runner ignores the busy flag", map[string]float64{"offline": 3, "online-busy": 2}, "offline", true, 3},
+		{"unmapped status is 0", onlineOnly, "offline", false, 0},
+		{"GitHub Enterprise Server status", map[string]float64{"idle": 1}, "idle", false, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runnerStatusValues = tt.statusValues
+			runner := &github.Runner{Status: github.Ptr(tt.status), Busy: github.Ptr(tt.busy)}
+			if got := getRunnerStatusValue(runner); got != tt.want {
+				t.Errorf("getRunnerStatusValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
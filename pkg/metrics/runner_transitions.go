@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	runnerStatusTransitionsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "github_runner_status_transitions_total",
+			Help: "Number of times a self-hosted runner went from online to offline or back, observed between collection cycles.",
+		},
+		[]string{"runner_id"},
+	)
+
+	runnerLastTransitionGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_runner_last_transition_timestamp_seconds",
+			Help: "Unix timestamp at which the exporter last saw a self-hosted runner go online or offline.",
+		},
+		[]string{"runner_id"},
+	)
+
+	runnerTransitions = newRunnerTransitionTracker()
+)
+
+// runnerTransitionTracker keeps the online state of runners from the previous cycle of each runner fetcher
+// ("repo", "organization", ...), as the API only gives the current status.
+type runnerTransitionTracker struct {
+	mutex  sync.Mutex
+	online map[string]map[int64]bool // Key: scope, then runner ID
+}
+
+func newRunnerTransitionTracker() *runnerTransitionTracker {
+	return &runnerTransitionTracker{online: make(map[string]map[int64]bool)}
+}
+
+// observe records the current state of a runner and counts a transition if it changed since the previous cycle.
+func (t *runnerTransitionTracker) observe(scope string, runnerID int64, online bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.online[scope] == nil {
+		t.online[scope] = make(map[int64]bool)
+	}
+	previous, known := t.online[scope][runnerID]
+	t.online[scope][runnerID] = online
+	if known && previous != online {
+		id := strconv.FormatInt(runnerID, 10)
+		runnerStatusTransitionsCounter.WithLabelValues(id).Inc()
+		runnerLastTransitionGauge.WithLabelValues(id).Set(float64(time.Now().Unix()))
+	}
+}
+
+// forgetMissing drops the state and series of runners of a scope that were not seen during the last cycle
+// (removed runners), so their IDs don't linger forever.
+func (t *runnerTransitionTracker) forgetMissing(scope string, seenRunnerIDs map[int64]bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for runnerID := range t.online[scope] {
+		if !seenRunnerIDs[runnerID] {
+			delete(t.online[scope], runnerID)
+			id := strconv.FormatInt(runnerID, 10)
+			runnerStatusTransitionsCounter.DeleteLabelValues(id)
+			runnerLastTransitionGauge.DeleteLabelValues(id)
+		}
+	}
+}
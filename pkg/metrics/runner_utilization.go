@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/google/go-github/v72/github"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	runnerUtilizationGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_runner_utilization_ratio",
+			Help: "Ratio of busy to online self-hosted runners of a repository or organization. Not set when no runner is online.",
+		},
+		[]string{"scope", "name"},
+	)
+
+	runnersOfflineGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_runners_offline_total",
+			Help: "Number of self-hosted runners of a repository or organization registered but not online, pointing to dead hosts holding registration slots.",
+		},
+		[]string{"scope", "name"},
+	)
+
+	// Names exported per scope by the last cycle, so the series of a name without runners
+	// can be deleted without resetting the other scope. Repository and organization fetchers run concurrently.
+	runnerUtilizationNamesMu sync.Mutex
+	runnerUtilizationNames   = make(map[string]map[string]bool)
+)
+
+type runnerCounts struct {
+	online  int
+	busy    int
+	offline int
+}
+
+// runnerUtilization counts online, busy and offline runners per repository or organization over a cycle.
+type runnerUtilization map[string]*runnerCounts
+
+func (u runnerUtilization) add(name string, runner *github.Runner) {
+	if u[name] == nil {
+		u[name] = &runnerCounts{}
+	}
+	if runner.GetStatus() != "online" {
+		u[name].offline++
+		return
+	}
+	u[name].online++
+	if runner.GetBusy() {
+		u[name].busy++
+	}
+}
+
+// export sets runnersOfflineGauge for the names of scope with runners, and runnerUtilizationGauge for those
+// with online runners. The series of the other names are deleted.
+func (u runnerUtilization) export(scope string) {
+	runnerUtilizationNamesMu.Lock()
+	defer runnerUtilizationNamesMu.Unlock()
+
+	exported := make(map[string]bool)
+	for name, counts := range u {
+		runnersOfflineGauge.WithLabelValues(scope, name).Set(float64(counts.offline))
+		exported[name] = true
+		if counts.online == 0 {
+			runnerUtilizationGauge.DeleteLabelValues(scope, name) // Avoid dividing by zero
+			continue
+		}
+		runnerUtilizationGauge.WithLabelValues(scope, name).Set(float64(counts.busy) / float64(counts.online))
+	}
+	for name := range runnerUtilizationNames[scope] {
+		if !exported[name] {
+			runnerUtilizationGauge.DeleteLabelValues(scope, name)
+			runnersOfflineGauge.DeleteLabelValues(scope, name)
+		}
+	}
+	runnerUtilizationNames[scope] = exported
+}
@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Key: "owner/repo", Value: fraction of its runs exported, parsed from SAMPLE_RATE_OVERRIDES.
+// Repositories without an override export all their runs.
+var sampleRates = make(map[string]float64)
+
+// parseSampleRateOverrides parses "owner/repo=rate" entries, rate being in (0, 1].
+func parseSampleRateOverrides(overrides []string) (map[string]float64, error) {
+	rates := make(map[string]float64)
+	for _, override := range overrides {
+		override = strings.TrimSpace(override)
+		if override == "" {
+			continue
+		}
+		repoFullName, rateValue, found := strings.Cut(override, "=")
+		repoFullName = strings.TrimSpace(repoFullName)
+		if !found || strings.Count(repoFullName, "/") != 1 {
+			return nil, fmt.Errorf("%q is not formatted as <owner>/<repo>=<rate>", override)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(rateValue), 64)
+		if err != nil || rate <= 0 || rate > 1 {
+			return nil, fmt.Errorf("sample rate of %s must be a number in (0, 1], got %q", repoFullName, rateValue)
+		}
+		rates[repoFullName] = rate
+	}
+	return rates, nil
+}
+
+// isRunSampled reports whether the metrics of a run are exported under the sample rate of its repository.
+// The decision is a hash of the run ID, so the same runs are sampled in every cycle.
+func isRunSampled(repoFullName string, runID int64) bool {
+	rate, ok := sampleRates[repoFullName]
+	if !ok || rate >= 1 {
+		return true
+	}
+	var id [8]byte
+	binary.BigEndian.PutUint64(id[:], uint64(runID))
+	hash := fnv.New64a()
+	hash.Write(id[:])
+	return float64(hash.Sum64())/math.MaxUint64 < rate
+}
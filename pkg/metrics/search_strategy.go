@@ -0,0 +1,159 @@
+package metrics
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v72/github"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+)
+
+// Values of FETCH_STRATEGY.
+const (
+	fetchStrategyRepo   = "repo"
+	fetchStrategySearch = "search"
+)
+
+// The code search API allows 10 requests per minute on github.com, far below the core rate limit, so its calls
+// are spaced out instead of sharing the pacing of the other fetchers. See getSearchRequestInterval.
+const searchRequestInterval = 6 * time.Second
+
+var (
+	// Key: "owner/repo", Value: file names of SEARCH_WORKFLOW_FILES found in its .github/workflows directory.
+	// Filled by refreshSearchedWorkflowFiles on each workflow cache refresh when FETCH_STRATEGY=search.
+	searchedWorkflowFiles   = make(map[string][]string)
+	searchedWorkflowFilesMu sync.RWMutex
+
+	lastSearchRequest time.Time
+)
+
+// validateFetchStrategy checks FETCH_STRATEGY and the options it requires.
+func validateFetchStrategy() error {
+	switch config.Github.FetchStrategy {
+	case fetchStrategyRepo:
+		return nil
+	case fetchStrategySearch:
+		if len(config.Github.SearchWorkflowFiles.Value()) == 0 {
+			return fmt.Errorf("strategy %s requires search_workflow_files (env: SEARCH_WORKFLOW_FILES)", fetchStrategySearch)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown strategy %q, expected %s or %s", config.Github.FetchStrategy, fetchStrategyRepo, fetchStrategySearch)
+}
+
+// waitForSearchRequest blocks until the next code search call is allowed by getSearchRequestInterval.
+func waitForSearchRequest() {
+	time.Sleep(time.Until(lastSearchRequest.Add(getSearchRequestInterval())))
+	lastSearchRequest = time.Now()
+}
+
+// searchWorkflowFile returns the repositories of an owner containing a workflow file. It returns false
+// when the results are partial (error, or more than the 1000 results the search API returns).
+func searchWorkflowFile(owner string, workflowFile string) ([]string, bool) {
+	query := fmt.Sprintf("filename:%s path:.github/workflows user:%s", workflowFile, owner)
+	opt := &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 100}} // Not getPerPage(): every page costs a search call
+	var repoFullNames []string
+	for {
+		waitForSearchRequest()
+		result, resp, err := getClient().Search.Code(fetcherContext(fetcherDiscovery), query, opt)
+		recordAPIError(err)
+		if rlErr, ok := err.(*github.RateLimitError); ok {
+			log.Printf("Code search ratelimited for %q. Pausing until %s", query, rlErr.Rate.Reset.Time.String())
+			sleepUntilRateLimitReset(rlErr.Rate.Reset.Time)
+			continue
+		} else if abuseErr, ok := err.(*github.AbuseRateLimitError); ok {
+			retryAfter := abuseErr.GetRetryAfter()
+			if retryAfter <= 0 {
+				retryAfter = time.Minute
+			}
+			log.Printf("Code search hit a secondary rate limit for %q. Pausing for %s", query, retryAfter)
+			time.Sleep(retryAfter)
+			continue
+		} else if err != nil {
+			log.Printf("Code search error for %q: %v", query, err)
+			return repoFullNames, false
+		}
+		if result == nil || result.GetIncompleteResults() {
+			log.Printf("Code search results for %q are incomplete.", query)
+			return repoFullNames, false
+		}
+		for _, codeResult := range result.CodeResults {
+			// The file name qualifier also matches nested paths, keep the workflow definitions only
+			if path.Dir(codeResult.GetPath()) == ".github/workflows" && codeResult.GetRepository().GetFullName() != "" {
+				repoFullNames = append(repoFullNames, codeResult.GetRepository().GetFullName())
+			}
+		}
+		recordPageFetched(resp)
+		if getNextPage(resp) == 0 {
+			break
+		}
+		opt.Page = getNextPage(resp)
+	}
+	return repoFullNames, true
+}
+
+// refreshSearchedWorkflowFiles finds which monitored repositories contain the workflows of SEARCH_WORKFLOW_FILES,
+// with one code search per owner and workflow file instead of listing the runs of every repository.
+// The previous results of an owner are kept when its search is partial.
+func refreshSearchedWorkflowFiles(repos []string) {
+	monitored := make(map[string]bool)
+	seenOwners := make(map[string]bool)
+	var owners []string
+	for _, repoFullName := range repos {
+		owner, _, found := strings.Cut(repoFullName, "/")
+		if !found {
+			continue
+		}
+		if !seenOwners[owner] {
+			seenOwners[owner] = true
+			owners = append(owners, owner)
+		}
+		monitored[repoFullName] = true
+	}
+
+	searchedWorkflowFilesMu.RLock()
+	previous := searchedWorkflowFiles
+	searchedWorkflowFilesMu.RUnlock()
+
+	found := make(map[string][]string)
+	for _, owner := range owners {
+		ownerFound := make(map[string][]string)
+		complete := true
+		for _, workflowFile := range config.Github.SearchWorkflowFiles.Value() {
+			repoFullNames, ok := searchWorkflowFile(owner, strings.TrimSpace(workflowFile))
+			complete = complete && ok
+			for _, repoFullName := range repoFullNames {
+				if monitored[repoFullName] {
+					ownerFound[repoFullName] = append(ownerFound[repoFullName], strings.TrimSpace(workflowFile))
+				}
+			}
+		}
+		if !complete {
+			for repoFullName, workflowFiles := range previous {
+				if _, ok := ownerFound[repoFullName]; !ok && strings.HasPrefix(repoFullName, owner+"/") && monitored[repoFullName] {
+					ownerFound[repoFullName] = workflowFiles
+				}
+			}
+		}
+		for repoFullName, workflowFiles := range ownerFound {
+			found[repoFullName] = workflowFiles
+		}
+	}
+
+	searchedWorkflowFilesMu.Lock()
+	searchedWorkflowFiles = found
+	searchedWorkflowFilesMu.Unlock()
+	log.Printf("periodicGithubFetcher: Code search found the configured workflows in %d of %d repositories.", len(found), len(repos))
+}
+
+// getSearchedWorkflowFiles returns the workflow files of a repository found by refreshSearchedWorkflowFiles.
+func getSearchedWorkflowFiles(repoFullName string) []string {
+	searchedWorkflowFilesMu.RLock()
+	defer searchedWorkflowFilesMu.RUnlock()
+	return searchedWorkflowFiles[repoFullName]
+}
@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"time"
+)
+
+// seenSet remembers IDs already accounted for in a counter, so an item fetched
+// again in a later cycle isn't counted twice. Each ID keeps the creation time of
+// its item so the set can be bounded by age with prune.
+type seenSet map[int64]time.Time
+
+// add marks an ID as seen. It reports false if the ID was already present.
+func (s seenSet) add(id int64, createdAt time.Time) bool {
+	if _, ok := s[id]; ok {
+		return false
+	}
+	s[id] = createdAt
+	return true
+}
+
+// has reports whether an ID was already seen.
+func (s seenSet) has(id int64) bool {
+	_, ok := s[id]
+	return ok
+}
+
+// prune forgets IDs of items created before the cutoff; they can't be fetched again.
+func (s seenSet) prune(cutoff time.Time) {
+	for id, createdAt := range s {
+		if createdAt.Before(cutoff) {
+			delete(s, id)
+		}
+	}
+}
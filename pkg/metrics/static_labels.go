@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+)
+
+// metricLabelNames are the fixed label names of the exporter metrics, which static labels can't reuse.
+// The workflow run fields (directFieldNames, derivedFieldNames) are reserved too. Keep in sync with the metrics.
+var metricLabelNames = []string{
+	"allowed_actions", "api_url", "attempt", "auth_mode", "branch", "caller_workflow", "category", "check_name", "class",
+	"commit", "enabled_repositories", "endpoint", "environment", "fetcher", "github_owned_allowed", "go_version", "html_url",
+	"id", "installation_id", "jobs_url", "labels", "le", "logs_url", "name", "org", "organization_name", "os", "os_type",
+	"previous_attempt_url", "quantile", "reason", "referenced_path", "referenced_sha", "repo_full_name", "resource",
+	"result", "runner_busy", "runner_id", "runner_name", "runner_os", "runner_type", "scale_set", "scope",
+	"verified_allowed", "version", "workflow_node_id", "workflow_state",
+}
+
+// parseStaticLabels parses "key=value" entries (STATIC_LABELS) into labels added to every exported metric.
+// Names already used by the labels of a metric are rejected, as registering it would fail.
+func parseStaticLabels(entries []string) (prometheus.Labels, error) {
+	reserved := make(map[string]bool)
+	for _, labelName := range append(append(append([]string{}, metricLabelNames...), directFieldNames...), derivedFieldNames...) {
+		reserved[labelName] = true
+	}
+	labels := make(prometheus.Labels)
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, found := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !found {
+			return nil, fmt.Errorf("%q is not formatted as <key>=<value>", entry)
+		}
+		if !model.LabelName(name).IsValid() || strings.HasPrefix(name, "__") {
+			return nil, fmt.Errorf("%q is not a valid label name", name)
+		}
+		if reserved[name] {
+			return nil, fmt.Errorf("label %q is already used by the exporter metrics, pick another name", name)
+		}
+		if _, duplicated := labels[name]; duplicated {
+			return nil, fmt.Errorf("label %q is set more than once", name)
+		}
+		labels[name] = strings.TrimSpace(value)
+	}
+	return labels, nil
+}
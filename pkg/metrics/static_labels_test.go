@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestParseStaticLabels(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []string
+		want    prometheus.Labels
+		wantErr bool
+	}{
+		{"no labels", nil, prometheus.Labels{}, false},
+		{"labels", []string{"environment_name=prod", " team = platform ", ""}, prometheus.Labels{"environment_name": "prod", "team": "platform"}, false},
+		{"empty value", []string{"team="}, prometheus.Labels{"team": ""}, false},
+		{"missing value", []string{"team"}, nil, true},
+		{"invalid name", []string{"my-team=platform"}, nil, true},
+		{"reserved prefix", []string{"__name__=foo"}, nil, true},
+		{"duplicated name", []string{"team=a", "team=b"}, nil, true},
+		{"label of the metrics", []string{"org=acme"}, nil, true},
+		{"histogram label", []string{"le=1"}, nil, true},
+		{"default workflow run field", []string{"repo=org/repo"}, nil, true},
+		{"opt-in workflow run field", []string{"head_sha=abc"}, nil, true},
+		{"derived workflow run field", []string{"dispatch_input=prod"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseStaticLabels(tt.entries)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseStaticLabels(%q) error = %v, wantErr %v", tt.entries, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseStaticLabels(%q) = %v, want %v", tt.entries, got, tt.want)
+			}
+		})
+	}
+}
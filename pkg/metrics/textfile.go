@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+// writeTextfile writes the current registry in the text exposition format to TEXTFILE_OUTPUT_PATH,
+// for the node_exporter textfile collector. It does nothing when the path is not set.
+func writeTextfile() {
+	if config.TextfileOutputPath == "" {
+		return
+	}
+	if err := writeTextfileAtomically(config.TextfileOutputPath); err != nil {
+		log.Printf("Writing metrics to textfile %s failed: %v", config.TextfileOutputPath, err)
+	}
+}
+
+// writeTextfileAtomically writes to a temporary file in the same directory and renames it over path,
+// so the textfile collector never reads a partially written file.
+func writeTextfileAtomically(path string) error {
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		return fmt.Errorf("gathering metrics: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	for _, family := range families {
+		if _, err := expfmt.MetricFamilyToText(tmp, family); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil { // CreateTemp uses 0600, the collector may run as another user
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,252 @@
+package metrics
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v72/github"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+	"github.com/markomanboi/github-actions-exporter/pkg/version"
+)
+
+// Maximum number of spans sent in a single OTLP request.
+const traceBatchSize = 500
+
+// OTLP span status codes.
+const (
+	otlpStatusOk    = 1
+	otlpStatusError = 2
+)
+
+// Key: run ID and attempt, Value: creation time of the run. Attempts whose spans were sent, so they
+// are emitted once. Only used by the workflow run collection goroutine.
+var emittedTraceRuns = make(map[workflowJobsCacheKey]time.Time)
+
+// OTLP/HTTP JSON encoding of a trace export request (opentelemetry-proto ExportTraceServiceRequest).
+// It needs no OpenTelemetry SDK: spans are built from already finished runs and jobs.
+type otlpTraceRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpAttribute struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpStatus struct {
+	Code int `json:"code"`
+}
+
+// traceBatch collects the spans of the runs completed during a workflow run collection cycle:
+// a span per run attempt, parent of a span per job when FETCH_WORKFLOW_JOBS is enabled.
+type traceBatch struct {
+	spans   []otlpSpan
+	pending map[workflowJobsCacheKey]time.Time // Run attempts of the spans, marked as emitted once sent
+}
+
+func newTraceBatch() *traceBatch {
+	return &traceBatch{pending: make(map[workflowJobsCacheKey]time.Time)}
+}
+
+// getTraceIDs derives the trace ID of a run attempt and the span ID of its root span from its identity,
+// so the same run always maps to the same trace.
+func getTraceIDs(repoFullName string, run *github.WorkflowRun) (string, string) {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s/%d/%d", repoFullName, run.GetID(), run.GetRunAttempt())))
+	return hex.EncodeToString(sum[:16]), hex.EncodeToString(sum[16:24])
+}
+
+func getJobSpanID(job *github.WorkflowJob) string {
+	sum := sha256.Sum256([]byte("job/" + strconv.FormatInt(job.GetID(), 10)))
+	return hex.EncodeToString(sum[:8])
+}
+
+func getSpanStatus(conclusion string) otlpStatus {
+	if failureConclusions[conclusion] {
+		return otlpStatus{Code: otlpStatusError}
+	}
+	return otlpStatus{Code: otlpStatusOk}
+}
+
+func stringAttribute(key string, value string) otlpAttribute {
+	return otlpAttribute{Key: key, Value: otlpAnyValue{StringValue: value}}
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// addRun adds the span of a completed run attempt not emitted yet.
+func (b *traceBatch) addRun(repoFullName string, workflowName string, run *github.WorkflowRun) {
+	key := workflowJobsCacheKey{run.GetID(), run.GetRunAttempt()}
+	if !config.Traces.Enabled || run.GetStatus() != "completed" {
+		return
+	}
+	if _, ok := emittedTraceRuns[key]; ok {
+		return
+	}
+	start := run.GetRunStartedAt().Time
+	if start.IsZero() {
+		start = run.GetCreatedAt().Time
+	}
+	end := run.GetUpdatedAt().Time
+	if start.IsZero() || end.Before(start) {
+		return
+	}
+
+	traceID, spanID := getTraceIDs(repoFullName, run)
+	b.spans = append(b.spans, otlpSpan{
+		TraceID:           traceID,
+		SpanID:            spanID,
+		Name:              workflowName,
+		Kind:              1, // Internal
+		StartTimeUnixNano: unixNano(start),
+		EndTimeUnixNano:   unixNano(end),
+		Attributes: []otlpAttribute{
+			stringAttribute("github.repository", repoFullName),
+			stringAttribute("github.workflow", workflowName),
+			stringAttribute("github.run_id", strconv.FormatInt(run.GetID(), 10)),
+			stringAttribute("github.run_attempt", strconv.Itoa(run.GetRunAttempt())),
+			stringAttribute("github.conclusion", run.GetConclusion()),
+			stringAttribute("github.head_branch", run.GetHeadBranch()),
+			stringAttribute("github.event", run.GetEvent()),
+			stringAttribute("github.actor", run.GetActor().GetLogin()),
+			stringAttribute("github.html_url", run.GetHTMLURL()),
+		},
+		Status: getSpanStatus(run.GetConclusion()),
+	})
+	b.pending[key] = run.GetCreatedAt().Time
+}
+
+// addJobs adds the spans of the completed jobs of a run added by addRun in this cycle.
+func (b *traceBatch) addJobs(repoFullName string, run *github.WorkflowRun, jobs []*github.WorkflowJob) {
+	if _, ok := b.pending[workflowJobsCacheKey{run.GetID(), run.GetRunAttempt()}]; !ok {
+		return
+	}
+	traceID, parentSpanID := getTraceIDs(repoFullName, run)
+	for _, job := range jobs {
+		if job == nil || job.GetStatus() != "completed" || job.StartedAt == nil || job.CompletedAt == nil {
+			continue
+		}
+		if job.GetCompletedAt().Time.Before(job.GetStartedAt().Time) {
+			continue
+		}
+		b.spans = append(b.spans, otlpSpan{
+			TraceID:           traceID,
+			SpanID:            getJobSpanID(job),
+			ParentSpanID:      parentSpanID,
+			Name:              job.GetName(),
+			Kind:              1, // Internal
+			StartTimeUnixNano: unixNano(job.GetStartedAt().Time),
+			EndTimeUnixNano:   unixNano(job.GetCompletedAt().Time),
+			Attributes: []otlpAttribute{
+				stringAttribute("github.repository", repoFullName),
+				stringAttribute("github.job_id", strconv.FormatInt(job.GetID(), 10)),
+				stringAttribute("github.conclusion", job.GetConclusion()),
+				stringAttribute("github.runner_name", job.GetRunnerName()),
+				stringAttribute("github.runner_labels", strings.Join(job.Labels, ",")),
+			},
+			Status: getSpanStatus(job.GetConclusion()),
+		})
+	}
+}
+
+// send exports the spans of the cycle to OTEL_EXPORTER_OTLP_ENDPOINT. Run attempts are marked as emitted
+// only once all their spans were accepted, so a failed export is retried on the next cycle.
+func (b *traceBatch) send() {
+	if len(b.spans) == 0 {
+		return
+	}
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	for start := 0; start < len(b.spans); start += traceBatchSize {
+		end := start + traceBatchSize
+		if end > len(b.spans) {
+			end = len(b.spans)
+		}
+		if err := sendTraceSpans(httpClient, b.spans[start:end]); err != nil {
+			log.Printf("Sending %d spans to %s failed: %v", end-start, config.Traces.Endpoint, err)
+			return
+		}
+	}
+	for key, createdAt := range b.pending {
+		emittedTraceRuns[key] = createdAt
+	}
+	log.Printf("Sent %d spans of %d workflow runs to %s.", len(b.spans), len(b.pending), config.Traces.Endpoint)
+}
+
+func sendTraceSpans(httpClient *http.Client, spans []otlpSpan) error {
+	request := otlpTraceRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource: otlpResource{Attributes: []otlpAttribute{stringAttribute("service.name", config.Traces.ServiceName)}},
+		ScopeSpans: []otlpScopeSpans{{
+			Scope: otlpScope{Name: "github-actions-exporter", Version: version.Version},
+			Spans: spans,
+		}},
+	}}}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Post(strings.TrimSuffix(config.Traces.Endpoint, "/")+"/v1/traces", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// pruneEmittedTraceRuns forgets the run attempts created before the cutoff; they can't be fetched again.
+func pruneEmittedTraceRuns(cutoff time.Time) {
+	for key, createdAt := range emittedTraceRuns {
+		if createdAt.Before(cutoff) {
+			delete(emittedTraceRuns, key)
+		}
+	}
+}
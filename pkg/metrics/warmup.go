@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+)
+
+var (
+	// Key: fetcher, Value: number of its cycles completed without being degraded. Guarded by warmupMu.
+	fetcherCompletedCycles = make(map[string]int)
+	warmupMu               sync.Mutex
+
+	// Set once every fetcher completed WARMUP_CYCLES cycles, never unset.
+	warmedUp atomic.Bool
+)
+
+// expectFetcherCycles registers a fetcher whose cycles gate IsWarmedUp. Fetchers call it once they know their
+// feature is configured, before their first cycle.
+func expectFetcherCycles(fetcher string) {
+	warmupMu.Lock()
+	defer warmupMu.Unlock()
+	if _, ok := fetcherCompletedCycles[fetcher]; !ok {
+		fetcherCompletedCycles[fetcher] = 0
+	}
+}
+
+// recordCompletedCycle counts a cycle of a fetcher towards WARMUP_CYCLES, unless every fetch of it failed.
+func recordCompletedCycle(fetcher string) {
+	degradedFetchersMu.Lock()
+	degraded := degradedFetchers[fetcher]
+	degradedFetchersMu.Unlock()
+	if degraded {
+		return
+	}
+	warmupMu.Lock()
+	defer warmupMu.Unlock()
+	fetcherCompletedCycles[fetcher]++
+}
+
+// IsWarmedUp reports whether /metrics can be served: every started fetcher completed WARMUP_CYCLES cycles,
+// so scrapes don't see the partial data of the first cycles. Always true when WARMUP_CYCLES is 0.
+func IsWarmedUp() bool {
+	if config.WarmupCycles <= 0 || warmedUp.Load() {
+		return true
+	}
+	warmupMu.Lock()
+	defer warmupMu.Unlock()
+	if len(fetcherCompletedCycles) == 0 {
+		return false // Fetchers not started yet
+	}
+	for _, cycles := range fetcherCompletedCycles {
+		if cycles < config.WarmupCycles {
+			return false
+		}
+	}
+	if warmedUp.CompareAndSwap(false, true) {
+		log.Printf("Every fetcher completed %d cycle(s), serving /metrics.", config.WarmupCycles)
+	}
+	return true
+}
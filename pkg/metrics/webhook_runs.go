@@ -0,0 +1,237 @@
+package metrics
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v72/github"
+)
+
+// webhookRunStore keeps the workflow runs and jobs received through webhook deliveries. A repository
+// that delivered an event within the fetch window is "webhook-driven": once seeded by one regular fetch,
+// its runs are served from the store and no longer listed through the API. The jobs of a run attempt are
+// likewise served from the store once seeded by one complete listing.
+// Deliveries of repositories that are not monitored are dropped, and everything is dropped once out of the fetch window.
+// It is shared by the webhook handler and the fetchers, hence the mutex.
+type webhookRunStore struct {
+	mu           sync.Mutex
+	monitored    map[string]bool                          // Key: "owner/repo", set by prune
+	lastDelivery map[string]time.Time                     // Key: "owner/repo"
+	seeded       map[string]bool                          // Key: "owner/repo"
+	runs         map[string]map[int64]*github.WorkflowRun // Key: "owner/repo", then run ID
+	jobs         map[int64]*webhookRunJobs                // Key: run ID
+}
+
+// webhookRunJobs holds the jobs of a run received through webhook deliveries or seeded by a listing.
+type webhookRunJobs struct {
+	repo           string
+	runCreated     time.Time                     // Creation of the run, or of its earliest job until seeded
+	seededAttempts map[int64]bool                // Attempts whose jobs were listed completely
+	jobs           map[int64]*github.WorkflowJob // Key: job ID
+}
+
+var webhookRuns = newWebhookRunStore()
+
+func newWebhookRunStore() *webhookRunStore {
+	return &webhookRunStore{
+		monitored:    make(map[string]bool),
+		lastDelivery: make(map[string]time.Time),
+		seeded:       make(map[string]bool),
+		runs:         make(map[string]map[int64]*github.WorkflowRun),
+		jobs:         make(map[int64]*webhookRunJobs),
+	}
+}
+
+// HandleWebhookEvent records a parsed webhook event. Events other than workflow_run and workflow_job are ignored:
+// GitHub has no runner status event, so the runner metrics are always polled.
+func HandleWebhookEvent(event interface{}) {
+	switch e := event.(type) {
+	case *github.WorkflowRunEvent:
+		if e.GetRepo().GetFullName() != "" && e.GetWorkflowRun() != nil {
+			webhookRuns.recordRun(e.GetRepo().GetFullName(), e.GetWorkflowRun())
+		}
+	case *github.WorkflowJobEvent:
+		if e.GetRepo().GetFullName() != "" && e.GetWorkflowJob() != nil {
+			webhookRuns.recordJob(e.GetRepo().GetFullName(), e.GetWorkflowJob())
+		}
+	}
+}
+
+// storeRun keeps run unless a more recently updated version of it is already stored. Callers hold the lock.
+func (s *webhookRunStore) storeRun(repoFullName string, run *github.WorkflowRun) {
+	if s.runs[repoFullName] == nil {
+		s.runs[repoFullName] = make(map[int64]*github.WorkflowRun)
+	}
+	if stored := s.runs[repoFullName][run.GetID()]; stored == nil || !run.GetUpdatedAt().Time.Before(stored.GetUpdatedAt().Time) {
+		s.runs[repoFullName][run.GetID()] = run
+	}
+}
+
+// runJobs returns the jobs entry of a run, creating it. Callers hold the lock.
+func (s *webhookRunStore) runJobs(repoFullName string, runID int64) *webhookRunJobs {
+	entry := s.jobs[runID]
+	if entry == nil {
+		entry = &webhookRunJobs{
+			repo:           repoFullName,
+			seededAttempts: make(map[int64]bool),
+			jobs:           make(map[int64]*github.WorkflowJob),
+		}
+		s.jobs[runID] = entry
+	}
+	return entry
+}
+
+func (s *webhookRunStore) recordRun(repoFullName string, run *github.WorkflowRun) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.monitored[repoFullName] {
+		return
+	}
+	if _, ok := s.lastDelivery[repoFullName]; !ok {
+		log.Printf("Received a first webhook delivery for %s, its workflow runs will be served from webhooks once seeded.", repoFullName)
+	}
+	s.lastDelivery[repoFullName] = time.Now()
+	s.storeRun(repoFullName, run)
+}
+
+func (s *webhookRunStore) recordJob(repoFullName string, job *github.WorkflowJob) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.monitored[repoFullName] {
+		return
+	}
+	s.lastDelivery[repoFullName] = time.Now()
+	entry := s.runJobs(repoFullName, job.GetRunID())
+	if createdAt := job.GetCreatedAt().Time; len(entry.seededAttempts) == 0 && (entry.runCreated.IsZero() || createdAt.Before(entry.runCreated)) {
+		entry.runCreated = createdAt
+	}
+	entry.jobs[job.GetID()] = job
+}
+
+// seed stores the runs of a regular fetch for a repository that delivered webhooks, so runs created
+// before the first delivery are known. It does nothing for repositories without deliveries.
+func (s *webhookRunStore) seed(repoFullName string, runs []*github.WorkflowRun) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.lastDelivery[repoFullName]; !ok {
+		return
+	}
+	for _, run := range runs {
+		if run != nil {
+			s.storeRun(repoFullName, run)
+		}
+	}
+	s.seeded[repoFullName] = true
+}
+
+// seedJobs stores the complete job listing of a run attempt for a repository that delivered webhooks,
+// so the jobs of that attempt are served from the store from then on. Jobs already delivered as completed are kept.
+func (s *webhookRunStore) seedJobs(repoFullName string, run *github.WorkflowRun, jobs []*github.WorkflowJob) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.lastDelivery[repoFullName]; !ok {
+		return
+	}
+	entry := s.runJobs(repoFullName, run.GetID())
+	entry.runCreated = run.GetCreatedAt().Time
+	for _, job := range jobs {
+		if job == nil {
+			continue
+		}
+		if stored := entry.jobs[job.GetID()]; stored == nil || stored.GetStatus() != "completed" {
+			entry.jobs[job.GetID()] = job
+		}
+	}
+	entry.seededAttempts[int64(run.GetRunAttempt())] = true
+}
+
+// prune records the monitored repositories, dropping everything stored for the others,
+// and drops the jobs of runs created before windowStart.
+func (s *webhookRunStore) prune(monitoredRepos []string, windowStart time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.monitored = make(map[string]bool, len(monitoredRepos))
+	for _, repoFullName := range monitoredRepos {
+		s.monitored[repoFullName] = true
+	}
+	for repoFullName := range s.lastDelivery {
+		if !s.monitored[repoFullName] {
+			delete(s.lastDelivery, repoFullName)
+			delete(s.seeded, repoFullName)
+			delete(s.runs, repoFullName)
+		}
+	}
+	for runID, entry := range s.jobs {
+		if !s.monitored[entry.repo] || entry.runCreated.Before(windowStart) {
+			delete(s.jobs, runID)
+		}
+	}
+}
+
+// getRuns returns the runs of a webhook-driven, seeded repository created after windowStart, newest first like
+// the API listing, dropping older ones. It reports false when the runs must be fetched through the API instead.
+func (s *webhookRunStore) getRuns(repoFullName string, windowStart time.Time) ([]*github.WorkflowRun, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if lastDelivery, ok := s.lastDelivery[repoFullName]; !ok || lastDelivery.Before(windowStart) {
+		// No deliveries within the fetch window: webhooks are not (or no longer) set up, poll again.
+		delete(s.lastDelivery, repoFullName)
+		delete(s.seeded, repoFullName)
+		for runID := range s.runs[repoFullName] {
+			delete(s.jobs, runID)
+		}
+		delete(s.runs, repoFullName)
+		return nil, false
+	}
+	if !s.seeded[repoFullName] {
+		return nil, false
+	}
+
+	var runs []*github.WorkflowRun
+	for runID, run := range s.runs[repoFullName] {
+		if run.GetCreatedAt().Time.Before(windowStart) {
+			delete(s.runs[repoFullName], runID)
+			delete(s.jobs, runID)
+			continue
+		}
+		runs = append(runs, run)
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].GetCreatedAt().Time.After(runs[j].GetCreatedAt().Time)
+	})
+	return runs, true
+}
+
+// getJobs returns the jobs of a run attempt from the store. It reports false until the attempt was seeded
+// (see seedJobs), the jobs delivered so far possibly being only part of them.
+func (s *webhookRunStore) getJobs(runID int64, attempt int) ([]*github.WorkflowJob, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry := s.jobs[runID]
+	if entry == nil || !entry.seededAttempts[int64(attempt)] {
+		return nil, false
+	}
+	var jobs []*github.WorkflowJob
+	for _, job := range entry.jobs {
+		if job.GetRunAttempt() == int64(attempt) {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, true
+}
+
+// getWorkflowRunsForRepo returns the runs of a repository from webhook deliveries when it sends them,
+// and fetches them through the API otherwise. See getWorkflowRunsToFetchFromRepo for the completeness flag.
+func getWorkflowRunsForRepo(owner string, repoName string) ([]*github.WorkflowRun, bool) {
+	repoFullName := owner + "/" + repoName
+	if runs, ok := webhookRuns.getRuns(repoFullName, getFetchWindowStart()); ok {
+		return runs, true
+	}
+	runs, complete := getWorkflowRunsToFetchFromRepo(owner, repoName)
+	if complete {
+		webhookRuns.seed(repoFullName, runs)
+	}
+	return runs, complete
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v72/github"
+)
+
+func TestWebhookRunStore(t *testing.T) {
+	now := time.Now()
+	windowStart := now.Add(-time.Hour)
+	newRun := func(id int64, created time.Time) *github.WorkflowRun {
+		return &github.WorkflowRun{ID: github.Ptr(id), RunAttempt: github.Ptr(1), CreatedAt: &github.Timestamp{Time: created}}
+	}
+	newJob := func(id int64, runID int64, created time.Time) *github.WorkflowJob {
+		return &github.WorkflowJob{ID: github.Ptr(id), RunID: github.Ptr(runID), RunAttempt: github.Ptr(int64(1)), CreatedAt: &github.Timestamp{Time: created}}
+	}
+
+	store := newWebhookRunStore()
+	store.prune([]string{"org/repo"}, windowStart)
+	store.recordRun("org/other", newRun(9, now)) // Not monitored
+	if _, ok := store.lastDelivery["org/other"]; ok {
+		t.Errorf("delivery of a repository that is not monitored was recorded")
+	}
+
+	older, newer := newRun(1, now.Add(-30*time.Minute)), newRun(2, now.Add(-time.Minute))
+	store.recordRun("org/repo", older)
+	store.seed("org/repo", []*github.WorkflowRun{newer})
+	runs, ok := store.getRuns("org/repo", windowStart)
+	if !ok || len(runs) != 2 || runs[0].GetID() != 2 || runs[1].GetID() != 1 {
+		t.Fatalf("getRuns() = %v, %v, want runs 2 then 1", runs, ok)
+	}
+
+	store.recordJob("org/repo", newJob(10, 2, now))
+	if jobs, ok := store.getJobs(2, 1); ok {
+		t.Errorf("getJobs() served %d delivered jobs before the run attempt was seeded", len(jobs))
+	}
+	store.seedJobs("org/repo", newer, []*github.WorkflowJob{newJob(10, 2, now), newJob(11, 2, now)})
+	if jobs, ok := store.getJobs(2, 1); !ok || len(jobs) != 2 {
+		t.Errorf("getJobs() = %d jobs, %v after seeding, want 2 jobs", len(jobs), ok)
+	}
+
+	store.recordJob("org/repo", newJob(20, 3, now.Add(-2*time.Hour))) // Its run never delivered nor listed
+	store.prune([]string{"org/repo"}, windowStart)
+	if _, ok := store.jobs[3]; ok {
+		t.Errorf("jobs of a run created before the fetch window were kept")
+	}
+	if _, ok := store.jobs[2]; !ok {
+		t.Errorf("jobs of a run in the fetch window were dropped")
+	}
+	store.prune(nil, windowStart)
+	if len(store.lastDelivery) != 0 || len(store.runs) != 0 || len(store.jobs) != 0 {
+		t.Errorf("state of repositories no longer monitored was kept")
+	}
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+)
+
+var (
+	workflowCacheEvictionsCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "github_workflow_cache_evictions_total",
+			Help: "Number of workflow definitions evicted from the cache to stay under MAX_CACHED_WORKFLOWS.",
+		},
+	)
+
+	// Key: "owner/repo", Value: last time the workflow run collector needed the cached workflows of the repository.
+	// Guarded by workflowsMu.
+	workflowsLastAccess = make(map[string]time.Time)
+)
+
+// evictCachedWorkflows drops the workflows of the least recently accessed repositories until the cache holds
+// at most MAX_CACHED_WORKFLOWS workflow definitions, sparing the repository keep. Evicted repositories are
+// fetched again on demand by ensureWorkflowsForRepo. The caller must hold workflowsMu for writing.
+func evictCachedWorkflows(keep string) {
+	limit := config.Github.MaxCachedWorkflows
+	if limit <= 0 {
+		return
+	}
+	cachedCount := 0
+	for _, repoWorkflows := range workflows {
+		cachedCount += len(repoWorkflows)
+	}
+	if cachedCount <= limit {
+		return
+	}
+
+	candidates := make([]string, 0, len(workflows))
+	for repoFullName := range workflows {
+		if repoFullName != keep {
+			candidates = append(candidates, repoFullName)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool { // Never accessed first
+		return workflowsLastAccess[candidates[i]].Before(workflowsLastAccess[candidates[j]])
+	})
+	for _, repoFullName := range candidates {
+		if cachedCount <= limit {
+			break
+		}
+		evicted := len(workflows[repoFullName])
+		delete(workflows, repoFullName)
+		delete(workflowsLastAccess, repoFullName)
+		cachedCount -= evicted
+		workflowCacheEvictionsCounter.Add(float64(evicted))
+	}
+}
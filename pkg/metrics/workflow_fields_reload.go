@@ -0,0 +1,109 @@
+package metrics
+
+import (
+	"log"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+)
+
+// Guards workflowRunFieldNames and the gauges labelled with them, which ReloadWorkflowFields replaces.
+// collectWorkflowRuns holds it for reading during a whole cycle.
+var workflowRunGaugesMu sync.RWMutex
+
+// workflowRunGauges is registered once in place of the workflow run gauges, so ReloadWorkflowFields can swap them.
+var workflowRunGauges = &workflowRunCollector{}
+
+// workflowRunCollector exports the current workflow run gauges. It describes no metric, making it an unchecked
+// collector: the registry then accepts gauges with other label names under the same metric names, which it refuses
+// once they were registered, even after unregistering them.
+type workflowRunCollector struct {
+	mu     sync.Mutex
+	gauges []*prometheus.GaugeVec
+}
+
+// Describe sends nothing, see workflowRunCollector.
+func (c *workflowRunCollector) Describe(chan<- *prometheus.Desc) {}
+
+// Collect sends the series of the current gauges.
+func (c *workflowRunCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	gauges := c.gauges
+	c.mu.Unlock()
+	for _, gauge := range gauges {
+		gauge.Collect(ch)
+	}
+}
+
+// setGauges replaces the exported gauges, ignoring nil ones.
+func (c *workflowRunCollector) setGauges(gauges ...*prometheus.GaugeVec) {
+	var current []*prometheus.GaugeVec
+	for _, gauge := range gauges {
+		if gauge != nil {
+			current = append(current, gauge)
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gauges = current
+}
+
+// newWorkflowRunGauges creates the workflow run gauges labelled with labelNames. The duration
+// gauges are nil unless FETCH_WORKFLOW_RUN_USAGE is enabled.
+func newWorkflowRunGauges(labelNames []string) (status, durationMs, durationSeconds *prometheus.GaugeVec) {
+	status = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_workflow_run_status",
+			Help: "Status of GitHub Actions workflow runs. Fetches runs created within the 'fetch_max_workflow_creation_age_hours'. " +
+				"Labels are defined by 'export_fields_workflow_run' config.",
+		},
+		labelNames,
+	)
+	if !config.Metrics.FetchWorkflowRunUsage {
+		return status, nil, nil
+	}
+
+	durationMs = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_workflow_run_duration_ms",
+			Help: "Deprecated, use github_workflow_run_duration_seconds. Duration of GitHub Actions workflow runs in milliseconds (-1 if unknown).",
+		},
+		labelNames, // Assuming duration uses the same labels for simplicity
+	)
+	durationSeconds = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_workflow_run_duration_seconds",
+			Help: "Duration of GitHub Actions workflow runs in seconds. Subject to the same fetching rules as run status.",
+		},
+		labelNames,
+	)
+	return status, durationMs, durationSeconds
+}
+
+// ReloadWorkflowFields changes the labels of the workflow run metrics (EXPORT_FIELDS_WORKFLOW_RUN) without
+// restarting: workflowRunGauges switches to new gauges labelled with the new fields, once the running collection
+// cycle finished. Their series are exported again from the next cycle. On error, the current fields and gauges are kept.
+func ReloadWorkflowFields(workflowFields string) error {
+	labelNames, err := parseWorkflowFields(workflowFields)
+	if err != nil {
+		return err
+	}
+
+	workflowRunGaugesMu.Lock()
+	defer workflowRunGaugesMu.Unlock()
+
+	previousFieldNames := workflowRunFieldNames
+	workflowRunFieldNames = labelNames
+	if err := validateDispatchInputConfig(); err != nil {
+		workflowRunFieldNames = previousFieldNames
+		return err
+	}
+
+	workflowRunStatusGauge, workflowRunDurationGauge, workflowRunDurationSecondsGauge = newWorkflowRunGauges(labelNames)
+	workflowRunGauges.setGauges(workflowRunStatusGauge, workflowRunDurationGauge, workflowRunDurationSecondsGauge)
+	config.WorkflowFields = workflowFields
+	log.Printf("Workflow run metrics now labelled with: %v", labelNames)
+	return nil
+}
@@ -0,0 +1,125 @@
+package metrics
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+)
+
+// setupWorkflowRunGauges registers workflow run gauges labelled with labelNames in a new registry,
+// restoring the package state when the test ends.
+func setupWorkflowRunGauges(t *testing.T, labelNames []string) *prometheus.Registry {
+	t.Helper()
+	previousRegisterer, previousFieldNames, previousCollector := registerer, workflowRunFieldNames, workflowRunGauges
+	previousStatus, previousDurationMs, previousDurationSeconds := workflowRunStatusGauge, workflowRunDurationGauge, workflowRunDurationSecondsGauge
+	previousUsage, previousFields := config.Metrics.FetchWorkflowRunUsage, config.WorkflowFields
+	t.Cleanup(func() {
+		registerer, workflowRunFieldNames, workflowRunGauges = previousRegisterer, previousFieldNames, previousCollector
+		workflowRunStatusGauge, workflowRunDurationGauge, workflowRunDurationSecondsGauge = previousStatus, previousDurationMs, previousDurationSeconds
+		config.Metrics.FetchWorkflowRunUsage, config.WorkflowFields = previousUsage, previousFields
+	})
+
+	registry := prometheus.NewRegistry()
+	registerer = registry
+	config.Metrics.FetchWorkflowRunUsage = true
+	workflowRunFieldNames = labelNames
+	workflowRunStatusGauge, workflowRunDurationGauge, workflowRunDurationSecondsGauge = newWorkflowRunGauges(labelNames)
+	workflowRunGauges = &workflowRunCollector{}
+	workflowRunGauges.setGauges(workflowRunStatusGauge, workflowRunDurationGauge, workflowRunDurationSecondsGauge)
+	registry.MustRegister(workflowRunGauges)
+	return registry
+}
+
+// gatheredLabelNames returns the label names of the series of a metric family, nil when it has none.
+func gatheredLabelNames(t *testing.T, registry *prometheus.Registry, name string) []string {
+	t.Helper()
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != name || len(family.GetMetric()) == 0 {
+			continue
+		}
+		var labelNames []string
+		for _, label := range family.GetMetric()[0].GetLabel() {
+			labelNames = append(labelNames, label.GetName())
+		}
+		return labelNames
+	}
+	return nil
+}
+
+func TestReloadWorkflowFields(t *testing.T) {
+	registry := setupWorkflowRunGauges(t, []string{"repo", "status"})
+	workflowRunStatusGauge.WithLabelValues("org/repo", "completed").Set(1)
+	if got := gatheredLabelNames(t, registry, "github_workflow_run_status"); !reflect.DeepEqual(got, []string{"repo", "status"}) {
+		t.Fatalf("github_workflow_run_status labels = %v before the reload", got)
+	}
+
+	if err := ReloadWorkflowFields("workflow_name,repo"); err != nil {
+		t.Fatalf("ReloadWorkflowFields() error = %v", err)
+	}
+	if want := []string{"workflow_name", "repo"}; !reflect.DeepEqual(workflowRunFieldNames, want) {
+		t.Errorf("workflowRunFieldNames = %v, want %v", workflowRunFieldNames, want)
+	}
+	if config.WorkflowFields != "workflow_name,repo" {
+		t.Errorf("config.WorkflowFields = %q, want the reloaded fields", config.WorkflowFields)
+	}
+	workflowRunStatusGauge.WithLabelValues("build", "org/repo").Set(1)
+	workflowRunDurationSecondsGauge.WithLabelValues("build", "org/repo").Set(60)
+	for _, name := range []string{"github_workflow_run_status", "github_workflow_run_duration_seconds"} {
+		// Label pairs are gathered sorted by name
+		if got, want := gatheredLabelNames(t, registry, name), []string{"repo", "workflow_name"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s labels = %v, want %v", name, got, want)
+		}
+	}
+
+	// Back to the initial fields, which the registry already exported
+	if err := ReloadWorkflowFields("repo,status"); err != nil {
+		t.Fatalf("ReloadWorkflowFields() back to the initial fields error = %v", err)
+	}
+	workflowRunStatusGauge.WithLabelValues("org/repo", "completed").Set(1)
+	if got := gatheredLabelNames(t, registry, "github_workflow_run_status"); !reflect.DeepEqual(got, []string{"repo", "status"}) {
+		t.Errorf("github_workflow_run_status labels = %v, want [repo status]", got)
+	}
+}
+
+func TestReloadWorkflowFieldsKeepsFieldsOnError(t *testing.T) {
+	previousKey := config.Metrics.DispatchInputKey
+	t.Cleanup(func() { config.Metrics.DispatchInputKey = previousKey })
+	config.Metrics.DispatchInputKey = ""
+
+	tests := []struct {
+		name           string
+		workflowFields string
+	}{
+		{"unknown field", "repo,not_a_field"},
+		{"duplicated field", "repo,repo"},
+		{"no field", " , "},
+		{"dispatch_input without dispatch_input_key", "repo,dispatch_input"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := setupWorkflowRunGauges(t, []string{"repo", "status"})
+			status := workflowRunStatusGauge
+
+			if err := ReloadWorkflowFields(tt.workflowFields); err == nil {
+				t.Fatalf("ReloadWorkflowFields(%q) error = nil, want an error", tt.workflowFields)
+			}
+			if want := []string{"repo", "status"}; !reflect.DeepEqual(workflowRunFieldNames, want) {
+				t.Errorf("workflowRunFieldNames = %v, want %v", workflowRunFieldNames, want)
+			}
+			if workflowRunStatusGauge != status {
+				t.Error("workflowRunStatusGauge was replaced")
+			}
+			workflowRunStatusGauge.WithLabelValues("org/repo", "completed").Set(1)
+			if got := gatheredLabelNames(t, registry, "github_workflow_run_status"); len(got) != 2 {
+				t.Errorf("github_workflow_run_status labels = %v, want the previous ones", got)
+			}
+		})
+	}
+}
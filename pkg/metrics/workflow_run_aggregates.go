@@ -0,0 +1,279 @@
+package metrics
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/go-github/v72/github"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics in this file are aggregated from the workflow runs fetched during a cycle,
+// without any additional API call.
+var (
+	workflowRunsPerSHAGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_workflow_runs_per_sha",
+			Help: "Highest number of runs of a workflow sharing the same head SHA in the fetch window. " +
+				"Values above 1 point to force-push or re-trigger storms.",
+		},
+		[]string{"repo", "workflow_name"},
+	)
+
+	workflowConcurrencyCancellationsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "github_workflow_concurrency_cancellations_total",
+			Help: "Number of runs of a workflow cancelled after a newer run of the same workflow and branch was created, " +
+				"the signature of a concurrency group with cancel-in-progress.",
+		},
+		[]string{"repo", "workflow_name"},
+	)
+
+	workflowConcurrencyPendingGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_workflow_concurrency_pending_runs",
+			Help: "Number of runs of a workflow pending because another run holds their concurrency group.",
+		},
+		[]string{"repo", "workflow_name"},
+	)
+
+	workflowLatestRunStatusGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_workflow_latest_run_status",
+			Help: "Status of the most recent run (by creation time) of a workflow on a branch in the fetch window, " +
+				"with the same values as github_workflow_run_status.",
+		},
+		[]string{"repo", "workflow_name", "branch"},
+	)
+
+	activeWorkflowsGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_active_workflows",
+			Help: "Number of distinct workflows of a repository with at least one run in the fetch window. " +
+				"Compare with github_repo_workflow_count to find unused workflows.",
+		},
+		[]string{"repo"},
+	)
+
+	workflowRunsCreatedCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "github_workflow_runs_created_total",
+			Help: "Number of workflow runs created in a repository, counted once per run when first fetched. " +
+				"Use increase() to analyze the creation rate, e.g. by hour of day.",
+		},
+		[]string{"repo"},
+	)
+
+	workflowRunsMissingStartTimeCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "github_workflow_runs_missing_start_time_total",
+			Help: "Number of in progress or completed workflow runs of a repository without a run_started_at, counted once per run when first seen without it.",
+		},
+		[]string{"repo"},
+	)
+
+	scheduledRunLastTimestampGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_scheduled_run_last_timestamp_seconds",
+			Help: "Creation time of the most recent run of a workflow triggered by its schedule. " +
+				"Kept after the run leaves the fetch window, so an alert can fire when a cron stops firing.",
+		},
+		[]string{"repo", "workflow_name"},
+	)
+
+	workflowRunStartDelayGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_workflow_run_start_delay_seconds",
+			Help: "Time between the creation and the start of the most recently created started run of a workflow in the fetch window. " +
+				"Re-run attempts are ignored, their start time being unrelated to the creation of the run.",
+		},
+		[]string{"repo", "workflow_name"},
+	)
+
+	// Cancelled runs already evaluated by countConcurrencyCancellations. Bounded by the fetch window.
+	seenCancelledRuns = make(seenSet)
+
+	// Runs already counted by countCreatedRuns. Bounded by the fetch window.
+	seenCreatedRuns = make(seenSet)
+
+	// Runs already counted by countMissingStartTimeRuns. Bounded by the fetch window.
+	seenMissingStartRuns = make(seenSet)
+
+	// Creation time of the latest scheduled run of each workflow seen since the start. Only accessed by the workflow run collector.
+	scheduledRunLastCreated = make(map[workflowKey]time.Time)
+)
+
+type workflowKey struct {
+	repo         string
+	workflowName string
+}
+
+// runsPerSHACounter counts runs per workflow and head SHA over a cycle.
+type runsPerSHACounter map[workflowKey]map[string]int
+
+func (c runsPerSHACounter) add(repo string, workflowName string, headSHA string) {
+	if headSHA == "" {
+		return
+	}
+	key := workflowKey{repo, workflowName}
+	if c[key] == nil {
+		c[key] = make(map[string]int)
+	}
+	c[key][headSHA]++
+}
+
+// export sets workflowRunsPerSHAGauge to the highest per-SHA count of each workflow.
+func (c runsPerSHACounter) export() {
+	workflowRunsPerSHAGauge.Reset()
+	for key, perSHA := range c {
+		highest := 0
+		for _, count := range perSHA {
+			if count > highest {
+				highest = count
+			}
+		}
+		workflowRunsPerSHAGauge.WithLabelValues(key.repo, key.workflowName).Set(float64(highest))
+	}
+}
+
+// activeWorkflowsCounter collects the distinct workflow IDs with runs of each repository over a cycle.
+type activeWorkflowsCounter map[string]map[int64]bool
+
+// addRepo makes a repository whose runs were fetched export 0 when none of its workflows ran.
+func (c activeWorkflowsCounter) addRepo(repo string) {
+	if c[repo] == nil {
+		c[repo] = make(map[int64]bool)
+	}
+}
+
+func (c activeWorkflowsCounter) add(repo string, run *github.WorkflowRun) {
+	c.addRepo(repo)
+	c[repo][run.GetWorkflowID()] = true
+}
+
+// export sets activeWorkflowsGauge to the number of distinct workflows with runs of each repository.
+func (c activeWorkflowsCounter) export() {
+	activeWorkflowsGauge.Reset()
+	for repo, workflowIDs := range c {
+		activeWorkflowsGauge.WithLabelValues(repo).Set(float64(len(workflowIDs)))
+	}
+}
+
+// runStartDelayTracker keeps the most recently created started run of each workflow over a cycle.
+type runStartDelayTracker map[workflowKey]*github.WorkflowRun
+
+func (t runStartDelayTracker) add(repo string, workflowName string, run *github.WorkflowRun) {
+	if run.GetRunAttempt() > 1 || run.GetRunStartedAt().IsZero() || run.GetCreatedAt().IsZero() {
+		return // Re-run, or not started yet
+	}
+	key := workflowKey{repo, workflowName}
+	if latest := t[key]; latest == nil || run.GetCreatedAt().After(latest.GetCreatedAt().Time) {
+		t[key] = run
+	}
+}
+
+// export sets workflowRunStartDelayGauge to the start delay of the tracked run of each workflow.
+func (t runStartDelayTracker) export() {
+	workflowRunStartDelayGauge.Reset()
+	for key, run := range t {
+		delay := run.GetRunStartedAt().Sub(run.GetCreatedAt().Time).Seconds()
+		workflowRunStartDelayGauge.WithLabelValues(key.repo, key.workflowName).Set(math.Max(delay, 0))
+	}
+}
+
+type latestRunKey struct {
+	repo         string
+	workflowName string
+	branch       string
+}
+
+// latestRunTracker keeps the most recent run of each workflow and branch over a cycle.
+type latestRunTracker map[latestRunKey]*github.WorkflowRun
+
+func (t latestRunTracker) add(repo string, workflowName string, run *github.WorkflowRun) {
+	key := latestRunKey{repo, workflowName, run.GetHeadBranch()}
+	if latest := t[key]; latest == nil || run.GetCreatedAt().Time.After(latest.GetCreatedAt().Time) {
+		t[key] = run
+	}
+}
+
+// export sets workflowLatestRunStatusGauge to the status of the latest run of each workflow and branch.
+func (t latestRunTracker) export() {
+	workflowLatestRunStatusGauge.Reset()
+	for key, run := range t {
+		workflowLatestRunStatusGauge.WithLabelValues(key.repo, key.workflowName, key.branch).Set(getRunNumericStatus(run))
+	}
+}
+
+// countConcurrencyCancellations counts the cancelled runs of a repository superseded by a newer run of the
+// same workflow and branch, created before the cancellation completed. GitHub doesn't report why a run was
+// cancelled, so a manual cancellation racing a new push is counted as well.
+func countConcurrencyCancellations(repoFullName string, runs []*github.WorkflowRun) {
+	for _, run := range runs {
+		if run == nil || run.GetStatus() != "completed" || run.GetConclusion() != "cancelled" {
+			continue
+		}
+		if !seenCancelledRuns.add(run.GetID(), run.GetCreatedAt().Time) {
+			continue
+		}
+		if isSupersededRun(run, runs) {
+			workflowConcurrencyCancellationsCounter.WithLabelValues(repoFullName, getFieldValue(repoFullName, *run, "workflow_name")).Inc()
+		}
+	}
+}
+
+// countCreatedRuns counts the runs of a repository not seen in previous cycles in workflowRunsCreatedCounter.
+func countCreatedRuns(repoFullName string, runs []*github.WorkflowRun) {
+	for _, run := range runs {
+		if run != nil && seenCreatedRuns.add(run.GetID(), run.GetCreatedAt().Time) {
+			workflowRunsCreatedCounter.WithLabelValues(repoFullName).Inc()
+		}
+	}
+}
+
+// recordScheduledRuns sets scheduledRunLastTimestampGauge from the runs of a repository triggered by a schedule.
+// Unlike the other aggregates, it is never reset: scheduled workflows may run less often than the fetch window.
+func recordScheduledRuns(repoFullName string, runs []*github.WorkflowRun) {
+	for _, run := range runs {
+		if run == nil || run.GetEvent() != "schedule" || run.GetCreatedAt().IsZero() {
+			continue
+		}
+		key := workflowKey{repoFullName, getFieldValue(repoFullName, *run, "workflow_name")}
+		if createdAt := run.GetCreatedAt().Time; createdAt.After(scheduledRunLastCreated[key]) {
+			scheduledRunLastCreated[key] = createdAt
+			scheduledRunLastTimestampGauge.WithLabelValues(key.repo, key.workflowName).Set(float64(createdAt.Unix()))
+		}
+	}
+}
+
+// countMissingStartTimeRuns counts the in progress or completed runs of a repository lacking
+// RunStartedAt, not seen in previous cycles, in workflowRunsMissingStartTimeCounter.
+func countMissingStartTimeRuns(repoFullName string, runs []*github.WorkflowRun) {
+	for _, run := range runs {
+		if run == nil || !run.GetRunStartedAt().IsZero() {
+			continue
+		}
+		if status := run.GetStatus(); status != "in_progress" && status != "completed" {
+			continue // Not started yet
+		}
+		if seenMissingStartRuns.add(run.GetID(), run.GetCreatedAt().Time) {
+			workflowRunsMissingStartTimeCounter.WithLabelValues(repoFullName).Inc()
+		}
+	}
+}
+
+// isSupersededRun reports whether another run of the same workflow and branch was created
+// after run and no later than its last update (when it was cancelled).
+func isSupersededRun(run *github.WorkflowRun, runs []*github.WorkflowRun) bool {
+	for _, other := range runs {
+		if other == nil || other.GetID() == run.GetID() ||
+			other.GetWorkflowID() != run.GetWorkflowID() || other.GetHeadBranch() != run.GetHeadBranch() {
+			continue
+		}
+		createdAt := other.GetCreatedAt().Time
+		if createdAt.After(run.GetCreatedAt().Time) && !createdAt.After(run.GetUpdatedAt().Time) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"strconv"
+
+	"github.com/google/go-github/v72/github"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Info gauges (always 1) carrying per-run details that are kept out of
+// the labels of github_workflow_run_status to bound its cardinality.
+var (
+	workflowRunRerunInfoGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_workflow_run_rerun_info",
+			Help: "Link to the previous attempt of re-run workflow runs (always 1). Only set for runs with run_attempt > 1.",
+		},
+		[]string{"repo", "run_id", "attempt", "previous_attempt_url"},
+	)
+
+	workflowRunLinksGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "github_workflow_run_links",
+			Help: "Links to the page, logs and jobs of workflow runs (always 1), to be joined on run_id from alert templates.",
+		},
+		[]string{"repo", "run_id", "html_url", "logs_url", "jobs_url"},
+	)
+)
+
+// setWorkflowRunInfo sets the info gauges of a run.
+func setWorkflowRunInfo(repoFullName string, run *github.WorkflowRun) {
+	if run.GetRunAttempt() > 1 && run.GetPreviousAttemptURL() != "" {
+		workflowRunRerunInfoGauge.WithLabelValues(
+			repoFullName,
+			strconv.FormatInt(run.GetID(), 10),
+			strconv.Itoa(run.GetRunAttempt()),
+			run.GetPreviousAttemptURL(),
+		).Set(1)
+	}
+	if run.GetHTMLURL() != "" {
+		workflowRunLinksGauge.WithLabelValues(
+			repoFullName,
+			strconv.FormatInt(run.GetID(), 10),
+			run.GetHTMLURL(),
+			run.GetLogsURL(),
+			run.GetJobsURL(),
+		).Set(1)
+	}
+}
+
+// resetWorkflowRunInfo clears the info gauges at the start of a cycle.
+func resetWorkflowRunInfo() {
+	workflowRunRerunInfoGauge.Reset()
+	workflowRunLinksGauge.Reset()
+}
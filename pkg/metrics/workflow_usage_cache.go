@@ -0,0 +1,123 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v72/github"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// workflowUsageMaxAge bounds how long a cached usage is trusted without new runs, in case
+// runs are missed by the run fetcher (dormant repositories, runs older than the fetch window, ...).
+const workflowUsageMaxAge = 24 * time.Hour
+
+// workflowUsageSettleDelay is how long after a run update the usage API may still report the usage from before it,
+// billable time being aggregated asynchronously. A usage fetched within it is fetched again.
+const workflowUsageSettleDelay = 10 * time.Minute
+
+// workflowUsageKey identifies a workflow definition.
+type workflowUsageKey struct {
+	repo       string
+	workflowID int64
+}
+
+// cachedWorkflowUsage is the usage of a workflow over the billing cycle as of fetchedAt.
+type cachedWorkflowUsage struct {
+	usage     *github.WorkflowUsage
+	fetchedAt time.Time
+}
+
+var (
+	billingAPICallsSavedCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "github_billing_api_calls_saved_total",
+			Help: "Number of workflow usage API calls skipped by the billing fetchers because the workflow had no new runs since its usage was last fetched.",
+		},
+	)
+
+	// Latest update of a run seen by the run fetcher, per workflow. Read by the billing fetchers.
+	workflowLastRunUpdate   = make(map[workflowUsageKey]time.Time)
+	workflowLastRunUpdateMu sync.Mutex
+
+	workflowUsageCache   = make(map[workflowUsageKey]cachedWorkflowUsage)
+	workflowUsageCacheMu sync.Mutex
+)
+
+// recordWorkflowRunUpdates remembers the latest run update of each workflow of a repository, so the
+// billing fetchers know which workflow usages may have changed. Runs are updated when they complete.
+func recordWorkflowRunUpdates(repoFullName string, runs []*github.WorkflowRun) {
+	workflowLastRunUpdateMu.Lock()
+	defer workflowLastRunUpdateMu.Unlock()
+	for _, run := range runs {
+		if run == nil {
+			continue
+		}
+		key := workflowUsageKey{repoFullName, run.GetWorkflowID()}
+		if updatedAt := run.GetUpdatedAt().Time; updatedAt.After(workflowLastRunUpdate[key]) {
+			workflowLastRunUpdate[key] = updatedAt
+		}
+	}
+}
+
+// getCachedWorkflowUsage returns the usage of a workflow, only calling the API when it may have changed
+// since the last fetch: it was fetched before a run of the workflow was updated plus workflowUsageSettleDelay,
+// the billing cycle (a calendar month) changed, or the cached usage is older than workflowUsageMaxAge.
+// It returns nil when the usage couldn't be fetched.
+func getCachedWorkflowUsage(owner string, repoName string, workflowID int64) *github.WorkflowUsage {
+	key := workflowUsageKey{owner + "/" + repoName, workflowID}
+	now := time.Now()
+
+	workflowLastRunUpdateMu.Lock()
+	lastRunUpdate := workflowLastRunUpdate[key]
+	workflowLastRunUpdateMu.Unlock()
+
+	workflowUsageCacheMu.Lock()
+	cached, ok := workflowUsageCache[key]
+	workflowUsageCacheMu.Unlock()
+	if ok && cached.fetchedAt.Sub(lastRunUpdate) >= workflowUsageSettleDelay && now.Sub(cached.fetchedAt) < workflowUsageMaxAge &&
+		isSameBillingMonth(cached.fetchedAt, now) {
+		billingAPICallsSavedCounter.Inc()
+		return cached.usage
+	}
+
+	usage := getWorkflowUsage(owner, repoName, workflowID)
+	if usage == nil {
+		return nil
+	}
+	workflowUsageCacheMu.Lock()
+	workflowUsageCache[key] = cachedWorkflowUsage{usage, now}
+	workflowUsageCacheMu.Unlock()
+	return usage
+}
+
+// pruneWorkflowUsageCache drops the cached usages and run updates of workflows no longer in the cached workflow
+// definitions (deleted workflows, repositories no longer monitored), keyed by repository then workflow ID.
+func pruneWorkflowUsageCache(cachedWorkflows map[string]map[int64]*github.Workflow) {
+	isCached := func(key workflowUsageKey) bool {
+		_, ok := cachedWorkflows[key.repo][key.workflowID]
+		return ok
+	}
+
+	workflowLastRunUpdateMu.Lock()
+	for key := range workflowLastRunUpdate {
+		if !isCached(key) {
+			delete(workflowLastRunUpdate, key)
+		}
+	}
+	workflowLastRunUpdateMu.Unlock()
+
+	workflowUsageCacheMu.Lock()
+	for key := range workflowUsageCache {
+		if !isCached(key) {
+			delete(workflowUsageCache, key)
+		}
+	}
+	workflowUsageCacheMu.Unlock()
+}
+
+// isSameBillingMonth reports whether two times fall in the same UTC calendar month.
+func isSameBillingMonth(a time.Time, b time.Time) bool {
+	a, b = a.UTC(), b.UTC()
+	return a.Year() == b.Year() && a.Month() == b.Month()
+}
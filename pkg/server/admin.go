@@ -0,0 +1,40 @@
+package server
+
+import (
+	"crypto/subtle"
+	"log"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+)
+
+// adminHandler - fastHTTP handler running an admin action
+// Requests must carry ADMIN_TOKEN in an "Authorization: Bearer <token>" header
+func adminHandler(action func()) fasthttp.RequestHandler {
+	return adminBodyHandler(func(string) error {
+		action()
+		return nil
+	})
+}
+
+// adminBodyHandler - fastHTTP handler running an admin action taking the request body as argument
+// An action error is answered with 400 Bad Request and the error message
+func adminBodyHandler(action func(body string) error) fasthttp.RequestHandler {
+	expected := []byte("Bearer " + config.AdminToken)
+	return func(ctx *fasthttp.RequestCtx) {
+		if subtle.ConstantTimeCompare(ctx.Request.Header.Peek(fasthttp.HeaderAuthorization), expected) != 1 {
+			log.Printf("admin: rejected %s from %s", ctx.Path(), ctx.RemoteAddr())
+			ctx.SetStatusCode(fasthttp.StatusUnauthorized)
+			return
+		}
+		if err := action(strings.TrimSpace(string(ctx.PostBody()))); err != nil {
+			log.Printf("admin: %s failed: %v", ctx.Path(), err)
+			ctx.SetStatusCode(fasthttp.StatusBadRequest)
+			ctx.WriteString(err.Error())
+			return
+		}
+		ctx.SetStatusCode(fasthttp.StatusNoContent)
+	}
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+)
+
+func TestAdminBodyHandler(t *testing.T) {
+	previousToken := config.AdminToken
+	t.Cleanup(func() { config.AdminToken = previousToken })
+	config.AdminToken = "secret"
+
+	tests := []struct {
+		name          string
+		authorization string
+		actionErr     error
+		wantStatus    int
+		wantBody      string // Body passed to the action, "" when it must not run
+	}{
+		{"missing token", "", nil, fasthttp.StatusUnauthorized, ""},
+		{"wrong token", "Bearer other", nil, fasthttp.StatusUnauthorized, ""},
+		{"action error", "Bearer secret", errors.New("unknown field(s) foo"), fasthttp.StatusBadRequest, "repo,foo"},
+		{"success", "Bearer secret", nil, fasthttp.StatusNoContent, "repo,foo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotBody string
+			handler := adminBodyHandler(func(body string) error {
+				gotBody = body
+				return tt.actionErr
+			})
+
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.Header.SetMethod(fasthttp.MethodPost)
+			ctx.Request.SetRequestURI("/admin/workflow-fields")
+			if tt.authorization != "" {
+				ctx.Request.Header.Set(fasthttp.HeaderAuthorization, tt.authorization)
+			}
+			ctx.Request.SetBodyString(" repo,foo\n")
+			handler(ctx)
+
+			if got := ctx.Response.StatusCode(); got != tt.wantStatus {
+				t.Errorf("status = %d, want %d", got, tt.wantStatus)
+			}
+			if gotBody != tt.wantBody {
+				t.Errorf("action body = %q, want %q", gotBody, tt.wantBody)
+			}
+			if tt.actionErr != nil && string(ctx.Response.Body()) != tt.actionErr.Error() {
+				t.Errorf("response body = %q, want the action error", ctx.Response.Body())
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/metrics"
+)
+
+// repositoriesHandler - fastHTTP handler listing the monitored repositories as JSON
+func repositoriesHandler(ctx *fasthttp.RequestCtx) {
+	body, err := json.Marshal(metrics.MonitoredRepositories())
+	if err != nil {
+		log.Printf("debug: encoding the monitored repositories failed: %v", err)
+		ctx.SetStatusCode(fasthttp.StatusInternalServerError)
+		return
+	}
+	ctx.SetContentType("application/json")
+	ctx.SetBody(body)
+}
@@ -0,0 +1,177 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+	"github.com/markomanboi/github-actions-exporter/pkg/version"
+)
+
+// remoteWriteBatchSize - maximum number of series sent in a single remote write request
+const remoteWriteBatchSize = 500
+
+type remoteWriteLabel struct {
+	name  string
+	value string
+}
+
+type remoteWriteSeries struct {
+	labels    []remoteWriteLabel
+	value     float64
+	timestamp int64 // milliseconds
+}
+
+// runRemoteWrite - periodically push a snapshot of the registry to the remote write endpoint
+func runRemoteWrite() {
+	interval := time.Duration(config.RemoteWrite.Interval) * time.Second
+	if interval <= 0 {
+		interval = time.Duration(config.Github.Refresh) * time.Second
+	}
+	log.Printf("remote write to %s every %v", config.RemoteWrite.URL, interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	for range ticker.C {
+		families, err := prometheus.DefaultGatherer.Gather()
+		if err != nil {
+			log.Printf("remote write: gathering metrics failed: %v", err)
+			continue
+		}
+		series := toRemoteWriteSeries(families, time.Now())
+		for start := 0; start < len(series); start += remoteWriteBatchSize {
+			end := start + remoteWriteBatchSize
+			if end > len(series) {
+				end = len(series)
+			}
+			if err := sendRemoteWrite(httpClient, series[start:end]); err != nil {
+				log.Printf("remote write: sending %d series failed: %v", end-start, err)
+				break // Retried with a fresh snapshot on next tick
+			}
+		}
+	}
+}
+
+// toRemoteWriteSeries - flatten metric families into samples, the way Prometheus would scrape them
+func toRemoteWriteSeries(families []*dto.MetricFamily, now time.Time) []remoteWriteSeries {
+	timestamp := now.UnixMilli()
+	var series []remoteWriteSeries
+	add := func(name string, metric *dto.Metric, value float64, extra ...remoteWriteLabel) {
+		labels := []remoteWriteLabel{{name: "__name__", value: name}}
+		for _, pair := range metric.GetLabel() {
+			labels = append(labels, remoteWriteLabel{name: pair.GetName(), value: pair.GetValue()})
+		}
+		labels = append(labels, extra...)
+		sort.Slice(labels, func(i, j int) bool { return labels[i].name < labels[j].name })
+		series = append(series, remoteWriteSeries{labels: labels, value: value, timestamp: timestamp})
+	}
+
+	for _, family := range families {
+		name := family.GetName()
+		for _, metric := range family.GetMetric() {
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				add(name, metric, metric.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				add(name, metric, metric.GetGauge().GetValue())
+			case dto.MetricType_UNTYPED:
+				add(name, metric, metric.GetUntyped().GetValue())
+			case dto.MetricType_HISTOGRAM:
+				histogram := metric.GetHistogram()
+				hasInfBucket := false
+				for _, bucket := range histogram.GetBucket() {
+					hasInfBucket = math.IsInf(bucket.GetUpperBound(), +1)
+					add(name+"_bucket", metric, float64(bucket.GetCumulativeCount()),
+						remoteWriteLabel{name: "le", value: strconv.FormatFloat(bucket.GetUpperBound(), 'g', -1, 64)})
+				}
+				if !hasInfBucket {
+					add(name+"_bucket", metric, float64(histogram.GetSampleCount()), remoteWriteLabel{name: "le", value: "+Inf"})
+				}
+				add(name+"_sum", metric, histogram.GetSampleSum())
+				add(name+"_count", metric, float64(histogram.GetSampleCount()))
+			case dto.MetricType_SUMMARY:
+				summary := metric.GetSummary()
+				for _, quantile := range summary.GetQuantile() {
+					add(name, metric, quantile.GetValue(),
+						remoteWriteLabel{name: "quantile", value: strconv.FormatFloat(quantile.GetQuantile(), 'g', -1, 64)})
+				}
+				add(name+"_sum", metric, summary.GetSampleSum())
+				add(name+"_count", metric, float64(summary.GetSampleCount()))
+			}
+		}
+	}
+	return series
+}
+
+// encodeWriteRequest - encode series as a prometheus.WriteRequest protobuf message
+//
+//	WriteRequest { repeated TimeSeries timeseries = 1; }
+//	TimeSeries   { repeated Label labels = 1; repeated Sample samples = 2; }
+//	Label        { string name = 1; string value = 2; }
+//	Sample       { double value = 1; int64 timestamp = 2; }
+func encodeWriteRequest(series []remoteWriteSeries) []byte {
+	var request []byte
+	for _, s := range series {
+		var timeSeries []byte
+		for _, label := range s.labels {
+			var l []byte
+			l = protowire.AppendTag(l, 1, protowire.BytesType)
+			l = protowire.AppendString(l, label.name)
+			l = protowire.AppendTag(l, 2, protowire.BytesType)
+			l = protowire.AppendString(l, label.value)
+			timeSeries = protowire.AppendTag(timeSeries, 1, protowire.BytesType)
+			timeSeries = protowire.AppendBytes(timeSeries, l)
+		}
+		var sample []byte
+		sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+		sample = protowire.AppendFixed64(sample, math.Float64bits(s.value))
+		sample = protowire.AppendTag(sample, 2, protowire.VarintType)
+		sample = protowire.AppendVarint(sample, uint64(s.timestamp))
+		timeSeries = protowire.AppendTag(timeSeries, 2, protowire.BytesType)
+		timeSeries = protowire.AppendBytes(timeSeries, sample)
+
+		request = protowire.AppendTag(request, 1, protowire.BytesType)
+		request = protowire.AppendBytes(request, timeSeries)
+	}
+	return request
+}
+
+// sendRemoteWrite - send one batch of series using the remote write 1.0 protocol (snappy compressed protobuf)
+func sendRemoteWrite(httpClient *http.Client, series []remoteWriteSeries) error {
+	body := s2.EncodeSnappy(nil, encodeWriteRequest(series))
+	req, err := http.NewRequest(http.MethodPost, config.RemoteWrite.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("User-Agent", "github-actions-exporter/"+version.Version)
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if config.RemoteWrite.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+config.RemoteWrite.BearerToken)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("server returned HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+	}
+	return nil
+}
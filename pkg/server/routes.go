@@ -1,13 +1,18 @@
 package server
 
 import (
+	"fmt"
 	"net/http/pprof"
 	rtp "runtime/pprof"
 	"strings"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/valyala/fasthttp"
 	"github.com/valyala/fasthttp/fasthttpadaptor"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+	"github.com/markomanboi/github-actions-exporter/pkg/metrics"
 )
 
 var (
@@ -18,9 +23,36 @@ var (
 	index   = fasthttpadaptor.NewFastHTTPHandlerFunc(pprof.Index)
 )
 
+// Values of METRICS_FORMAT.
+const (
+	metricsFormatOpenMetrics = "openmetrics"
+	metricsFormatText        = "text"
+)
+
+// validateMetricsFormat checks METRICS_FORMAT.
+func validateMetricsFormat(format string) error {
+	switch format {
+	case metricsFormatOpenMetrics, metricsFormatText:
+		return nil
+	}
+	return fmt.Errorf("unknown format %q, expected %s or %s", format, metricsFormatOpenMetrics, metricsFormatText)
+}
+
 // prometheusHandler - fastHTTP handler for prometheus metrics
+// OpenMetrics is served to scrapers asking for it in their Accept header, unless METRICS_FORMAT=text
 func prometheusHandler() fasthttp.RequestHandler {
-	return fasthttpadaptor.NewFastHTTPHandler(promhttp.Handler())
+	handler := promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{
+		EnableOpenMetrics: config.MetricsFormat == metricsFormatOpenMetrics,
+	})
+	metricsHandler := fasthttpadaptor.NewFastHTTPHandler(promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer, handler))
+	return func(ctx *fasthttp.RequestCtx) {
+		if !metrics.IsWarmedUp() { // WARMUP_CYCLES
+			ctx.SetStatusCode(fasthttp.StatusServiceUnavailable)
+			ctx.WriteString("Warming up: the first collection cycles are not complete yet.")
+			return
+		}
+		metricsHandler(ctx)
+	}
 }
 
 func pprofHandlerIndex(ctx *fasthttp.RequestCtx) {
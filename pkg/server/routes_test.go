@@ -0,0 +1,71 @@
+package server
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+)
+
+func TestValidateMetricsFormat(t *testing.T) {
+	tests := []struct {
+		format  string
+		wantErr bool
+	}{
+		{"openmetrics", false},
+		{"text", false},
+		{"", true},
+		{"OpenMetrics", true},
+		{"txt", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			if err := validateMetricsFormat(tt.format); (err != nil) != tt.wantErr {
+				t.Errorf("validateMetricsFormat(%q) error = %v, wantErr %v", tt.format, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPrometheusHandlerFormat(t *testing.T) {
+	previousFormat, previousWarmup := config.MetricsFormat, config.WarmupCycles
+	t.Cleanup(func() { config.MetricsFormat, config.WarmupCycles = previousFormat, previousWarmup })
+	config.WarmupCycles = 0
+
+	// As sent by Prometheus, prometheus/common only negotiates OpenMetrics 0.0.1
+	const openMetricsAccept = "application/openmetrics-text;version=0.0.1;q=0.875,text/plain;version=0.0.4;q=0.5,*/*;q=0.1"
+	tests := []struct {
+		name            string
+		metricsFormat   string
+		accept          string
+		wantContentType string // Prefix of the Content-Type header
+	}{
+		{"openmetrics requested", "openmetrics", openMetricsAccept, "application/openmetrics-text"},
+		{"openmetrics without Accept header", "openmetrics", "", "text/plain"},
+		{"openmetrics with text requested", "openmetrics", "text/plain", "text/plain"},
+		{"text with openmetrics requested", "text", openMetricsAccept, "text/plain"},
+		{"text without Accept header", "text", "", "text/plain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.MetricsFormat = tt.metricsFormat
+			handler := prometheusHandler()
+
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.SetRequestURI("/metrics")
+			if tt.accept != "" {
+				ctx.Request.Header.Set(fasthttp.HeaderAccept, tt.accept)
+			}
+			handler(ctx)
+
+			if got := ctx.Response.StatusCode(); got != fasthttp.StatusOK {
+				t.Fatalf("status = %d, want %d", got, fasthttp.StatusOK)
+			}
+			if got := string(ctx.Response.Header.ContentType()); !strings.HasPrefix(got, tt.wantContentType) {
+				t.Errorf("Content-Type = %q, want a %q prefix", got, tt.wantContentType)
+			}
+		})
+	}
+}
@@ -8,19 +8,37 @@ import (
 	"github.com/urfave/cli/v2"
 	"github.com/valyala/fasthttp"
 
-	"github.com/spendesk/github-actions-exporter/pkg/config"
-	"github.com/spendesk/github-actions-exporter/pkg/metrics"
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+	"github.com/markomanboi/github-actions-exporter/pkg/metrics"
 )
 
 // RunServer - run http server for expose metrics
 func RunServer(ctx *cli.Context) error {
+	if err := validateMetricsFormat(config.MetricsFormat); err != nil {
+		log.Fatalf("Error: Invalid configuration 'metrics_format' (env: METRICS_FORMAT): %v", err)
+	}
 	metrics.InitMetrics()
+	if config.RunOnce {
+		if config.Pushgateway.URL == "" && config.TextfileOutputPath == "" {
+			log.Print("RUN_ONCE is set without PUSHGATEWAY_URL or TEXTFILE_OUTPUT_PATH: metrics are collected but not exported anywhere")
+		}
+		metrics.CollectOnce()
+		return nil
+	}
 
 	r := router.New()
 	r.GET("/", func(ctx *fasthttp.RequestCtx) {
 		ctx.WriteString("/metrics")
 	})
 	r.GET("/metrics", prometheusHandler())
+	if config.WebhookSecret != "" {
+		r.POST("/webhook", webhookHandler)
+	}
+	if config.AdminToken != "" {
+		r.POST("/admin/pause", adminHandler(metrics.PauseCollection))
+		r.POST("/admin/resume", adminHandler(metrics.ResumeCollection))
+		r.POST("/admin/workflow-fields", adminBodyHandler(metrics.ReloadWorkflowFields))
+	}
 
 	if config.Debug {
 		r.GET("/debug/pprof/", pprofHandlerIndex)
@@ -28,6 +46,11 @@ func RunServer(ctx *cli.Context) error {
 		r.GET("/debug/pprof/profile", pprofHandlerIndex)
 		r.GET("/debug/pprof/trace", pprofHandlerTrace)
 		r.GET("/debug/pprof/{profile}", pprofHandlerIndex)
+		r.GET("/debug/repositories", repositoriesHandler)
+	}
+
+	if config.RemoteWrite.URL != "" {
+		go runRemoteWrite()
 	}
 
 	log.Print("exporter listening on 0.0.0.0:" + strconv.Itoa(config.Port))
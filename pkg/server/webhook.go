@@ -0,0 +1,33 @@
+package server
+
+import (
+	"log"
+
+	"github.com/google/go-github/v72/github"
+	"github.com/valyala/fasthttp"
+
+	"github.com/markomanboi/github-actions-exporter/pkg/config"
+	"github.com/markomanboi/github-actions-exporter/pkg/metrics"
+)
+
+// webhookHandler - fastHTTP handler for GitHub webhook deliveries (workflow_run and workflow_job events, others are ignored)
+// Deliveries must be signed with WEBHOOK_SECRET
+func webhookHandler(ctx *fasthttp.RequestCtx) {
+	payload := ctx.PostBody()
+	signature := string(ctx.Request.Header.Peek(github.SHA256SignatureHeader))
+	if err := github.ValidateSignature(signature, payload, []byte(config.WebhookSecret)); err != nil {
+		log.Printf("webhook: rejected delivery %s: %v", ctx.Request.Header.Peek(github.DeliveryIDHeader), err)
+		ctx.SetStatusCode(fasthttp.StatusUnauthorized)
+		return
+	}
+
+	eventType := string(ctx.Request.Header.Peek(github.EventTypeHeader))
+	event, err := github.ParseWebHook(eventType, payload)
+	if err != nil {
+		log.Printf("webhook: cannot parse %s delivery: %v", eventType, err)
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		return
+	}
+	metrics.HandleWebhookEvent(event)
+	ctx.SetStatusCode(fasthttp.StatusAccepted)
+}
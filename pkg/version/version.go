@@ -0,0 +1,23 @@
+package version
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// Set at build time with -ldflags "-X github.com/markomanboi/github-actions-exporter/pkg/version.Version=..."
+var (
+	Version   = "development"
+	Commit    = "unknown"
+	BuildDate = "unknown"
+)
+
+// GoVersion - version of the Go toolchain the exporter was built with
+func GoVersion() string {
+	return runtime.Version()
+}
+
+// String - human readable version information
+func String() string {
+	return fmt.Sprintf("version %s, commit %s, built %s with %s", Version, Commit, BuildDate, GoVersion())
+}